@@ -10,4 +10,14 @@ integration {
     name = "Linode"
     slug = "linode"
   }
+  component {
+    type = "post-processor"
+    name = "Linode Import"
+    slug = "import"
+  }
+  component {
+    type = "post-processor"
+    name = "Linode Image Retention"
+    slug = "image-retention"
+  }
 }
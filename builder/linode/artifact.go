@@ -2,8 +2,12 @@ package linode
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"maps"
+	"strconv"
+	"strings"
 
 	registryimage "github.com/hashicorp/packer-plugin-sdk/packer/registry/image"
 	"github.com/linode/linodego"
@@ -41,7 +45,10 @@ func (a Artifact) Destroy() error {
 	return err
 }
 
-func (a Artifact) stateHCPPackerRegistryMetadata() interface{} {
+// stateHCPPackerRegistryMetadata returns a registry image per region the
+// image is available in, or for the build region only when the regions are
+// unknown.
+func (a Artifact) stateHCPPackerRegistryMetadata() []*registryimage.Image {
 	// create labels map
 	labels := make(map[string]string)
 	// get and set sourceImage from stateData into labels
@@ -59,16 +66,113 @@ func (a Artifact) stateHCPPackerRegistryMetadata() interface{} {
 	if ok {
 		labels["linode_type"] = linodeType
 	}
-	// create the image from artifact
-	image, err := registryimage.FromArtifact(a,
-		registryimage.WithProvider("linode"),
-		registryimage.WithID(a.ImageID),
-		registryimage.WithSourceID(sourceImage),
-		registryimage.WithRegion(region))
-	if err != nil {
-		log.Printf("[DEBUG] error encountered when creating registry image %s", err)
+	// get and set disk_encryption from stateData into labels
+	diskEncryption, ok := a.StateData["disk_encryption"].(string)
+	if ok && diskEncryption != "" {
+		labels["disk_encryption"] = diskEncryption
+	}
+	// get and set kernel from stateData into labels
+	kernel, ok := a.StateData["kernel"].(string)
+	if ok && kernel != "" {
+		labels["kernel"] = kernel
+	}
+	// get and set the cloud-init capability from stateData into labels
+	cloudInit, ok := a.StateData["cloud_init"].(bool)
+	if ok {
+		labels["cloud_init"] = strconv.FormatBool(cloudInit)
+	}
+	// get and set image_size (MB) from stateData into labels
+	imageSize, ok := a.StateData["image_size"].(int)
+	if ok && imageSize > 0 {
+		labels["image_size"] = strconv.Itoa(imageSize)
+	}
+	// get and set image_share_group_ids from stateData into labels
+	shareGroupIDs, ok := a.StateData["image_share_group_ids"].([]int)
+	if ok && len(shareGroupIDs) > 0 {
+		ids := make([]string, len(shareGroupIDs))
+		for i, id := range shareGroupIDs {
+			ids[i] = strconv.Itoa(id)
+		}
+		labels["image_share_group_ids"] = strings.Join(ids, ",")
+	}
+	// get and set instance_tags from stateData into labels
+	instanceTags, ok := a.StateData["instance_tags"].([]string)
+	if ok && len(instanceTags) > 0 {
+		labels["instance_tags"] = strings.Join(instanceTags, ",")
+	}
+
+	regions, _ := a.StateData["image_regions"].([]string)
+	if len(regions) == 0 {
+		regions = []string{region}
+	}
+
+	// create an image from artifact per region
+	images := make([]*registryimage.Image, 0, len(regions))
+	for _, r := range regions {
+		image, err := registryimage.FromArtifact(a,
+			registryimage.WithProvider("linode"),
+			registryimage.WithID(a.ImageID),
+			registryimage.WithSourceID(sourceImage),
+			registryimage.WithRegion(r))
+		if err != nil {
+			log.Printf("[DEBUG] error encountered when creating registry image %s", err)
+			return nil
+		}
+		image.Labels = maps.Clone(labels)
+		images = append(images, image)
+	}
+	return images
+}
+
+// CompositeArtifact is the artifact of a build that created an image per
+// disk. The first artifact is the image of the boot disk.
+type CompositeArtifact struct {
+	Artifacts []Artifact
+}
+
+func (a CompositeArtifact) BuilderId() string { return BuilderID }
+func (a CompositeArtifact) Files() []string   { return nil }
+
+// Id returns the comma-separated IDs of all the images.
+func (a CompositeArtifact) Id() string {
+	ids := make([]string, len(a.Artifacts))
+	for i, artifact := range a.Artifacts {
+		ids[i] = artifact.ImageID
+	}
+	return strings.Join(ids, ",")
+}
+
+func (a CompositeArtifact) String() string {
+	images := make([]string, len(a.Artifacts))
+	for i, artifact := range a.Artifacts {
+		images[i] = fmt.Sprintf("%s (%s)", artifact.ImageLabel, artifact.ImageID)
+	}
+	return "Linode images: " + strings.Join(images, ", ")
+}
+
+// State returns the registry metadata of all the images, or the state of
+// the boot disk image for any other name.
+func (a CompositeArtifact) State(name string) interface{} {
+	if name == registryimage.ArtifactStateURI {
+		var images []*registryimage.Image
+		for _, artifact := range a.Artifacts {
+			images = append(images, artifact.stateHCPPackerRegistryMetadata()...)
+		}
+		return images
+	}
+
+	if len(a.Artifacts) == 0 {
 		return nil
 	}
-	image.Labels = labels
-	return image
+	return a.Artifacts[0].State(name)
+}
+
+func (a CompositeArtifact) Destroy() error {
+	var errs []error
+	for _, artifact := range a.Artifacts {
+		if err := artifact.Destroy(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
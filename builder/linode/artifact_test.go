@@ -69,9 +69,10 @@ func TestArtifactState_hcpPackerRegistryMetadata(t *testing.T) {
 		ImageID:    "test-image",
 		ImageLabel: "test-image-label",
 		StateData: map[string]interface{}{
-			"source_image": "linode/arch",
-			"region":       region,
-			"linode_type":  "g6-nanode-1",
+			"source_image":    "linode/arch",
+			"region":          region,
+			"linode_type":     "g6-nanode-1",
+			"disk_encryption": "enabled",
 		},
 	}
 	// result should contain "something"
@@ -81,25 +82,110 @@ func TestArtifactState_hcpPackerRegistryMetadata(t *testing.T) {
 	}
 
 	// check for proper decoding of result into slice of registryimage.Image
-	var image registryimage.Image
-	err := mapstructure.Decode(result, &image)
+	var images []registryimage.Image
+	err := mapstructure.Decode(result, &images)
 	if err != nil {
 		t.Errorf("Bad: unexpected error when trying to decode state into registryimage.Image %v", err)
 	}
 
 	// check that all properties of the images were set correctly
-	expected := registryimage.Image{
-		ImageID:        "test-image",
-		ProviderName:   "linode",
-		ProviderRegion: "us-ord",
-		SourceImageID:  "linode/arch",
-		Labels: map[string]string{
-			"source_image": "linode/arch",
-			"region":       region,
-			"linode_type":  "g6-nanode-1",
+	expected := []registryimage.Image{
+		{
+			ImageID:        "test-image",
+			ProviderName:   "linode",
+			ProviderRegion: "us-ord",
+			SourceImageID:  "linode/arch",
+			Labels: map[string]string{
+				"source_image":    "linode/arch",
+				"region":          region,
+				"linode_type":     "g6-nanode-1",
+				"disk_encryption": "enabled",
+			},
 		},
 	}
-	if !reflect.DeepEqual(image, expected) {
-		t.Fatalf("Bad: expected %#v got %#v", expected, image)
+	if !reflect.DeepEqual(images, expected) {
+		t.Fatalf("Bad: expected %#v got %#v", expected, images)
+	}
+}
+
+func TestArtifactState_hcpPackerRegistryMetadataRegions(t *testing.T) {
+	artifact := &Artifact{
+		ImageID:    "test-image",
+		ImageLabel: "test-image-label",
+		StateData: map[string]interface{}{
+			"source_image":          "linode/debian12",
+			"region":                "us-ord",
+			"linode_type":           "g6-nanode-1",
+			"image_regions":         []string{"us-ord", "eu-central"},
+			"kernel":                "linode/grub2",
+			"cloud_init":            true,
+			"image_size":            2500,
+			"image_share_group_ids": []int{12, 34},
+			"instance_tags":         []string{"team=platform", "nightly"},
+		},
+	}
+
+	images, ok := artifact.State(registryimage.ArtifactStateURI).([]*registryimage.Image)
+	if !ok || len(images) != 2 {
+		t.Fatalf("Bad: expected HCP Packer registry data for 2 regions, got %#v", images)
+	}
+
+	expectedLabels := map[string]string{
+		"source_image":          "linode/debian12",
+		"region":                "us-ord",
+		"linode_type":           "g6-nanode-1",
+		"kernel":                "linode/grub2",
+		"cloud_init":            "true",
+		"image_size":            "2500",
+		"image_share_group_ids": "12,34",
+		"instance_tags":         "team=platform,nightly",
+	}
+	for i, region := range []string{"us-ord", "eu-central"} {
+		if images[i].ImageID != "test-image" || images[i].ProviderRegion != region {
+			t.Errorf("Bad: unexpected image %s in %s", images[i].ImageID, images[i].ProviderRegion)
+		}
+		if !reflect.DeepEqual(images[i].Labels, expectedLabels) {
+			t.Errorf("Bad: expected labels %#v got %#v", expectedLabels, images[i].Labels)
+		}
+	}
+}
+
+func TestCompositeArtifact(t *testing.T) {
+	var raw interface{} = &CompositeArtifact{}
+	if _, ok := raw.(packersdk.Artifact); !ok {
+		t.Fatalf("CompositeArtifact should be artifact")
+	}
+
+	stateData := map[string]interface{}{
+		"source_image": "linode/arch",
+		"region":       "us-ord",
+		"linode_type":  "g6-nanode-1",
+	}
+	artifact := &CompositeArtifact{
+		Artifacts: []Artifact{
+			{ImageID: "private/42", ImageLabel: "packer-foobar", StateData: stateData},
+			{ImageID: "private/43", ImageLabel: "packer-foobar-data", StateData: stateData},
+		},
+	}
+
+	if artifact.Id() != "private/42,private/43" {
+		t.Errorf("Bad: artifact ID was %s", artifact.Id())
+	}
+
+	expected := "Linode images: packer-foobar (private/42), packer-foobar-data (private/43)"
+	if artifact.String() != expected {
+		t.Errorf("Bad: artifact string was %s", artifact.String())
+	}
+
+	if artifact.State("region") != "us-ord" {
+		t.Errorf("Bad: State should return the state of the boot disk image")
+	}
+
+	images, ok := artifact.State(registryimage.ArtifactStateURI).([]*registryimage.Image)
+	if !ok || len(images) != 2 {
+		t.Fatalf("Bad: expected HCP Packer registry data for 2 images, got %#v", images)
+	}
+	if images[0].ImageID != "private/42" || images[1].ImageID != "private/43" {
+		t.Errorf("Bad: unexpected image IDs %s and %s", images[0].ImageID, images[1].ImageID)
 	}
 }
@@ -0,0 +1,16 @@
+package linode
+
+import "slices"
+
+// BuildMarkerTag is applied to the temporary resources of a build, and to its
+// images until the build succeeds, so that the resources left behind by builds
+// that could not clean up can be found and deleted by packer-linode-sweep.
+const BuildMarkerTag = "packer-linode-build"
+
+// withBuildMarker returns the tags with BuildMarkerTag added.
+func withBuildMarker(tags []string) []string {
+	if slices.Contains(tags, BuildMarkerTag) {
+		return tags
+	}
+	return append(slices.Clone(tags), BuildMarkerTag)
+}
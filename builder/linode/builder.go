@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"slices"
 
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/linode/linodego"
@@ -15,6 +16,7 @@ import (
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	"github.com/hashicorp/packer-plugin-sdk/multistep/commonsteps"
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/packerbuilderdata"
 	"github.com/linode/packer-plugin-linode/helper"
 )
 
@@ -34,20 +36,27 @@ func (b *Builder) Prepare(raws ...any) ([]string, []string, error) {
 	if errs != nil {
 		return nil, warnings, errs
 	}
-	return nil, warnings, nil
+
+	generatedData := []string{
+		"InstanceID",
+		"InstanceLabel",
+		"InstanceIPv4",
+		"InstanceIPv6",
+		"PrivateIPv4",
+		"DiskID",
+		"SourceImage",
+		"SourceImageUpdated",
+		"Region",
+	}
+
+	return generatedData, warnings, nil
 }
 
 func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook) (ret packersdk.Artifact, err error) {
 	ui.Say("Running builder ...")
-	var client *linodego.Client
-
-	if b.config.APICAPath != "" {
-		client, err = helper.NewLinodeClientWithCA(b.config.PersonalAccessToken, b.config.APICAPath)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		client = helper.NewLinodeClient(b.config.PersonalAccessToken)
+	client, err := b.config.NewClient()
+	if err != nil {
+		return nil, err
 	}
 
 	state := new(multistep.BasicStateBag)
@@ -55,73 +64,171 @@ func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook)
 	state.Put("hook", hook)
 	state.Put("ui", ui)
 
+	leaks := &leakedResources{}
+	state.Put("leaked_resources", leaks)
+
+	generatedData := &packerbuilderdata.GeneratedData{State: state}
+
 	steps := []multistep.Step{
 		&StepCreateSSHKey{
 			Debug:        b.config.PackerDebug,
 			DebugKeyPath: fmt.Sprintf("linode_%s.pem", b.config.PackerBuildName),
 		},
-		&stepCreateLinode{client},
-		&stepCreateDiskConfig{client},
-		&communicator.StepConnect{
-			Config:    &b.config.Comm,
-			Host:      commHost(b.config.Comm.Host()),
-			SSHConfig: b.config.Comm.SSHConfigFunc(),
-		},
-		&commonsteps.StepProvision{},
+	}
+
+	if b.config.TemporaryFirewall {
+		steps = append(steps, &stepCreateFirewall{client: client})
+	}
+
+	steps = append(steps,
+		&stepCreateLinode{client: client, generatedData: generatedData},
+		&stepCreateDiskConfig{client: client, generatedData: generatedData},
+	)
+
+	if len(b.config.Volumes) > 0 {
+		steps = append(steps, &stepAttachVolumes{client: client})
+	}
+
+	if b.config.Rescue != nil {
+		steps = append(steps, &stepBootRescue{client})
+	}
+
+	steps = append(steps, &communicator.StepConnect{
+		Config:    &b.config.Comm,
+		Host:      commHost(client, &b.config),
+		SSHConfig: b.config.Comm.SSHConfigFunc(),
+	})
+
+	if b.config.Rescue != nil {
+		steps = append(steps, &stepMountChroot{})
+	}
+
+	steps = append(steps, &commonsteps.StepProvision{})
+
+	if b.config.ImageShrink {
+		steps = append(steps, &stepMeasureDiskUsage{})
+	}
+
+	steps = append(steps,
 		&commonsteps.StepCleanupTempKeys{
 			Comm: &b.config.Comm,
 		},
-		&stepShutdownLinode{client},
-		&stepCreateImage{client},
+	)
+
+	if b.config.Rescue != nil {
+		steps = append(steps, &stepLeaveRescue{client})
 	}
 
+	steps = append(steps, &stepShutdownLinode{client})
+
+	if b.config.ImageShrink {
+		steps = append(steps, &stepShrinkDisk{client})
+	}
+
+	if b.config.ImageForceReplace {
+		steps = append(steps, &stepFindExistingImages{client})
+	}
+
+	steps = append(steps, &stepCreateImage{client: client})
+
 	b.runner = commonsteps.NewRunner(steps, b.config.PackerConfig, ui)
 	b.runner.Run(ctx, state)
 
+	// Resources the cleanup left behind are reported along with the outcome
+	// of the build
+	leakErr := leaks.report(b.config.LeakedResourcesFile)
+
 	if rawErr, ok := state.GetOk("error"); ok {
-		return nil, rawErr.(error)
+		return nil, errors.Join(rawErr.(error), leakErr)
 	}
 
 	// If we were interrupted or cancelled, then just exit.
 	if _, ok := state.GetOk(multistep.StateCancelled); ok {
-		return nil, errors.New("build was cancelled")
+		return nil, errors.Join(errors.New("build was cancelled"), leakErr)
 	}
 
 	if _, ok := state.GetOk(multistep.StateHalted); ok {
-		return nil, errors.New("build was halted")
+		return nil, errors.Join(errors.New("build was halted"), leakErr)
+	}
+
+	if leakErr != nil {
+		ui.Error(leakErr.Error())
 	}
 
 	if _, ok := state.GetOk("image"); !ok {
 		return nil, errors.New("cannot find image in state")
 	}
 
-	image := state.Get("image").(*linodego.Image)
-	artifact := Artifact{
-		ImageLabel: image.Label,
-		ImageID:    image.ID,
-		Driver:     client,
-		StateData: map[string]any{
-			"generated_data": state.Get("generated_data"),
-			"source_image":   b.config.Image,
-			"region":         b.config.Region,
-			"linode_type":    b.config.InstanceType,
-		},
+	instance := state.Get("instance").(*linodego.Instance)
+	images := state.Get("images").([]*linodego.Image)
+
+	var replaced map[string][]string
+	if v, ok := state.GetOk("replaced_image_ids"); ok {
+		replaced = v.(map[string][]string)
+	}
+
+	artifacts := make([]Artifact, len(images))
+	for i, image := range images {
+		available, failed := helper.ImageRegions(image, b.config.ImageRegions)
+		artifacts[i] = Artifact{
+			ImageLabel: image.Label,
+			ImageID:    image.ID,
+			Driver:     client,
+			StateData: map[string]any{
+				"generated_data":                   state.Get("generated_data"),
+				"source_image":                     b.config.Image,
+				"region":                           instance.Region,
+				"linode_type":                      state.Get("linode_type"),
+				"disk_encryption":                  string(instance.DiskEncryption),
+				"image_regions":                    available,
+				"image_replication_failed_regions": failed,
+				"image_tags":                       image.Tags,
+				"replaced_image_ids":               replaced[image.ID],
+				"kernel":                           bootKernel(&b.config),
+				"cloud_init":                       slices.Contains(image.Capabilities, "cloud-init"),
+				"image_size":                       image.Size,
+				"image_share_group_ids":            b.config.ImageShareGroupIDs,
+				"instance_tags":                    b.config.Tags,
+			},
+		}
+	}
+
+	if len(artifacts) > 1 {
+		return CompositeArtifact{Artifacts: artifacts}, nil
 	}
 
-	return artifact, nil
+	return artifacts[0], nil
+}
+
+// bootKernel returns the kernel the instance booted with, if configured.
+func bootKernel(c *Config) string {
+	if c.Kernel != "" {
+		return c.Kernel
+	}
+	if bootConfig := c.getBootConfig(); bootConfig != nil {
+		return bootConfig.Kernel
+	}
+	return ""
 }
 
-func commHost(host string) func(multistep.StateBag) (string, error) {
+func commHost(client *linodego.Client, c *Config) func(multistep.StateBag) (string, error) {
 	return func(state multistep.StateBag) (string, error) {
-		if host != "" {
+		if host := c.Comm.Host(); host != "" {
 			log.Printf("Using host value: %s", host)
 			return host, nil
 		}
 
 		instance := state.Get("instance").(*linodego.Instance)
-		if len(instance.IPv4) == 0 {
-			return "", fmt.Errorf("linode instance %d has no IPv4 addresses", instance.ID)
+		ips, err := client.GetInstanceIPAddresses(context.TODO(), instance.ID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get IP addresses of linode instance %d: %w", instance.ID, err)
+		}
+
+		host, err := resolveSSHAddress(c, instance.ID, ips)
+		if err != nil {
+			return "", err
 		}
-		return instance.IPv4[0].String(), nil
+		log.Printf("Using SSH address: %s", host)
+		return host, nil
 	}
 }
@@ -45,6 +45,34 @@ func TestBuilder_Prepare_BadType(t *testing.T) {
 	}
 }
 
+func TestBuilderPrepare_GeneratedData(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	generatedData, warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	expected := []string{
+		"InstanceID",
+		"InstanceLabel",
+		"InstanceIPv4",
+		"InstanceIPv6",
+		"PrivateIPv4",
+		"DiskID",
+		"SourceImage",
+		"SourceImageUpdated",
+		"Region",
+	}
+	if !reflect.DeepEqual(generatedData, expected) {
+		t.Errorf("got %v, expected %v", generatedData, expected)
+	}
+}
+
 func TestBuilderPrepare_InvalidKey(t *testing.T) {
 	var b Builder
 	config := testConfig()
@@ -736,6 +764,621 @@ func TestBuilderPrepare_PrivateIP(t *testing.T) {
 	}
 }
 
+func TestBuilderPrepare_SSHInterface(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Test default
+	_, warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if b.config.SSHInterface != "" {
+		t.Errorf("expected empty ssh_interface, got %q", b.config.SSHInterface)
+	}
+
+	// Test invalid value
+	config["ssh_interface"] = "bogus"
+	b = Builder{}
+	if _, _, err = b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Test private_ipv4 without private_ip
+	config["ssh_interface"] = "private_ipv4"
+	b = Builder{}
+	if _, _, err = b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	config["private_ip"] = true
+	b = Builder{}
+	if _, _, err = b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	// Test vpc_ipv4 without a VPC interface
+	config["ssh_interface"] = "vpc_ipv4"
+	b = Builder{}
+	if _, _, err = b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	config["linode_interface"] = []map[string]any{
+		{"vpc": map[string]any{"subnet_id": 123}},
+	}
+	b = Builder{}
+	if _, _, err = b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	// Test vlan without an IPAM address
+	config["ssh_interface"] = "vlan"
+	b = Builder{}
+	if _, _, err = b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_SourceLinode(t *testing.T) {
+	sourceConfig := func() map[string]any {
+		return map[string]any{
+			"linode_token":     "bar",
+			"region":           "us-ord",
+			"instance_type":    "g6-nanode-1",
+			"ssh_username":     "root",
+			"ssh_password":     "hunter2",
+			"source_linode_id": 123,
+		}
+	}
+
+	var b Builder
+	config := sourceConfig()
+	config["source_linode_disk_ids"] = []int{1, 2}
+	config["source_linode_config_ids"] = []int{3}
+
+	_, warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if b.config.SourceLinodeID != 123 {
+		t.Errorf("got %d, expected 123", b.config.SourceLinodeID)
+	}
+	if !reflect.DeepEqual(b.config.SourceLinodeDiskIDs, []int{1, 2}) {
+		t.Errorf("got %v, expected [1 2]", b.config.SourceLinodeDiskIDs)
+	}
+	if !reflect.DeepEqual(b.config.SourceLinodeConfigIDs, []int{3}) {
+		t.Errorf("got %v, expected [3]", b.config.SourceLinodeConfigIDs)
+	}
+
+	// Conflicts with image
+	config = sourceConfig()
+	config["image"] = "linode/debian12"
+	b = Builder{}
+	if _, _, err = b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Requires a way to authenticate without the temporary SSH key
+	config = sourceConfig()
+	delete(config, "ssh_password")
+	b = Builder{}
+	if _, _, err = b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Disk and config IDs require a source Linode
+	config = testConfig()
+	config["source_linode_disk_ids"] = []int{1}
+	b = Builder{}
+	if _, _, err = b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_ImageDisks(t *testing.T) {
+	imageDisksConfig := func() map[string]any {
+		config := testConfig()
+		delete(config, "image")
+		delete(config, "authorized_keys")
+		config["disk"] = []map[string]any{
+			{
+				"label":           "boot",
+				"size":            25000,
+				"image":           "linode/debian12",
+				"authorized_keys": []string{"ssh-rsa AAAA..."},
+			},
+			{
+				"label": "data",
+				"size":  10000,
+			},
+			{
+				"label":      "swap",
+				"size":       512,
+				"filesystem": "swap",
+			},
+		}
+		config["config"] = []map[string]any{
+			{
+				"label": "boot-config",
+				"devices": map[string]any{
+					"sda": map[string]any{"disk_label": "boot"},
+					"sdb": map[string]any{"disk_label": "data"},
+					"sdc": map[string]any{"disk_label": "swap"},
+				},
+			},
+		}
+		config["image_label"] = "packer-app"
+		config["image_description"] = "app"
+		return config
+	}
+
+	var b Builder
+	config := imageDisksConfig()
+	config["image_disks"] = []map[string]any{
+		{"disk_label": "data"},
+	}
+
+	_, warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	expected := []ImageDisk{
+		{DiskLabel: "data", ImageLabel: "packer-app-data", Description: "app"},
+	}
+	if !reflect.DeepEqual(b.config.ImageDisks, expected) {
+		t.Errorf("got %#v, expected %#v", b.config.ImageDisks, expected)
+	}
+
+	for name, imageDisks := range map[string][]map[string]any{
+		"unknown disk": {{"disk_label": "missing"}},
+		"boot disk":    {{"disk_label": "boot"}},
+		"swap disk":    {{"disk_label": "swap"}},
+		"duplicate":    {{"disk_label": "data"}, {"disk_label": "data"}},
+		"no label":     {{"image_label": "packer-app-data"}},
+	} {
+		config := imageDisksConfig()
+		config["image_disks"] = imageDisks
+		b = Builder{}
+		if _, _, err := b.Prepare(config); err == nil {
+			t.Errorf("%s: should have error", name)
+		}
+	}
+
+	// Requires custom disks
+	config = testConfig()
+	config["image_disks"] = []map[string]any{
+		{"disk_label": "data"},
+	}
+	b = Builder{}
+	if _, _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_Volumes(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	config["volume"] = []map[string]any{
+		{"label": "cache", "size": 20},
+		{"volume_id": 123},
+		{"label": "scratch", "size": 10, "delete_on_cleanup": false},
+	}
+
+	_, warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	// Created volumes are deleted by default, existing volumes are kept
+	for i, expected := range []bool{true, false, false} {
+		if got := *b.config.Volumes[i].DeleteOnCleanup; got != expected {
+			t.Errorf("volume %d: got delete_on_cleanup %t, expected %t", i, got, expected)
+		}
+	}
+
+	for name, volume := range map[string]map[string]any{
+		"missing label":      {"size": 20},
+		"too small":          {"label": "cache", "size": 5},
+		"label with id":      {"volume_id": 123, "label": "cache"},
+		"size with id":       {"volume_id": 123, "size": 20},
+		"missing label size": {},
+	} {
+		config := testConfig()
+		config["volume"] = []map[string]any{volume}
+		b = Builder{}
+		if _, _, err := b.Prepare(config); err == nil {
+			t.Errorf("%s: should have error", name)
+		}
+	}
+}
+
+func TestBuilderPrepare_PlacementGroup(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	config["instance_label"] = "packer-build"
+	config["placement_group"] = map[string]any{"type": "anti_affinity:local"}
+
+	_, warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	// Temporary placement groups default to the instance label and strict policy
+	if b.config.PlacementGroup.Label != "packer-build" {
+		t.Errorf("got label %q, expected %q", b.config.PlacementGroup.Label, "packer-build")
+	}
+	if b.config.PlacementGroup.Policy != "strict" {
+		t.Errorf("got policy %q, expected %q", b.config.PlacementGroup.Policy, "strict")
+	}
+
+	for _, pg := range []map[string]any{
+		{"id": 123},
+		{"label": "existing"},
+		{"type": "affinity:local", "label": "temporary", "policy": "flexible"},
+	} {
+		config := testConfig()
+		config["placement_group"] = pg
+		b = Builder{}
+		if _, _, err := b.Prepare(config); err != nil {
+			t.Errorf("%v: should not have error: %s", pg, err)
+		}
+	}
+
+	for name, pg := range map[string]map[string]any{
+		"empty":          {},
+		"id and label":   {"id": 123, "label": "existing"},
+		"id and type":    {"id": 123, "type": "anti_affinity:local"},
+		"policy with id": {"id": 123, "policy": "strict"},
+		"invalid type":   {"type": "spread"},
+		"invalid policy": {"type": "anti_affinity:local", "policy": "loose"},
+	} {
+		config := testConfig()
+		config["placement_group"] = pg
+		b = Builder{}
+		if _, _, err := b.Prepare(config); err == nil {
+			t.Errorf("%s: should have error", name)
+		}
+	}
+}
+
+func TestBuilderPrepare_DiskEncryption(t *testing.T) {
+	for _, value := range []string{"enabled", "disabled"} {
+		var b Builder
+		config := testConfig()
+		config["disk_encryption"] = value
+
+		_, warnings, err := b.Prepare(config)
+		if len(warnings) > 0 {
+			t.Fatalf("bad: %#v", warnings)
+		}
+		if err != nil {
+			t.Fatalf("%s: should not have error: %s", value, err)
+		}
+		if b.config.DiskEncryption != value {
+			t.Errorf("got %q, expected %q", b.config.DiskEncryption, value)
+		}
+	}
+
+	var b Builder
+	config := testConfig()
+	config["disk_encryption"] = "yes"
+	if _, _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error for an invalid value")
+	}
+
+	// Cloned Linodes keep the encryption of their source
+	b = Builder{}
+	config = testConfig()
+	delete(config, "image")
+	config["source_linode_id"] = 123
+	config["ssh_password"] = "password"
+	config["disk_encryption"] = "enabled"
+	if _, _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error with source_linode_id")
+	}
+}
+
+func TestBuilderPrepare_ImageReplication(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	if _, _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if b.config.ImageReplicationTimeout != 30*time.Minute {
+		t.Errorf("got timeout %s, expected 30m", b.config.ImageReplicationTimeout)
+	}
+	if b.config.ImageReplicationFailure != "fail" {
+		t.Errorf("got policy %q, expected fail", b.config.ImageReplicationFailure)
+	}
+
+	for _, value := range []string{"fail", "warn", "ignore"} {
+		b = Builder{}
+		config = testConfig()
+		config["image_replication_timeout"] = "1h"
+		config["image_replication_failure"] = value
+
+		if _, _, err := b.Prepare(config); err != nil {
+			t.Fatalf("%s: should not have error: %s", value, err)
+		}
+		if b.config.ImageReplicationFailure != value {
+			t.Errorf("got %q, expected %q", b.config.ImageReplicationFailure, value)
+		}
+		if b.config.ImageReplicationTimeout != time.Hour {
+			t.Errorf("got timeout %s, expected 1h", b.config.ImageReplicationTimeout)
+		}
+	}
+
+	b = Builder{}
+	config = testConfig()
+	config["image_replication_failure"] = "retry"
+	if _, _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error for an invalid policy")
+	}
+}
+
+func TestBuilderPrepare_InstanceTypeFallbacks(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	config["instance_type_fallbacks"] = []string{"g6-standard-2", "g6-dedicated-2"}
+
+	_, warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	expected := []string{"g6-nanode-1", "g6-standard-2", "g6-dedicated-2"}
+	if got := instanceTypes(&b.config); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+
+	for name, fallbacks := range map[string][]string{
+		"empty":                 {""},
+		"duplicate":             {"g6-standard-2", "g6-standard-2"},
+		"same as instance_type": {"g6-nanode-1"},
+	} {
+		config := testConfig()
+		config["instance_type_fallbacks"] = fallbacks
+		b = Builder{}
+		if _, _, err := b.Prepare(config); err == nil {
+			t.Errorf("%s: should have error", name)
+		}
+	}
+}
+
+func TestBuilderPrepare_Regions(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	config["region"] = "auto"
+	config["regions"] = []string{"us-ord", "us-mia"}
+	config["region_capabilities"] = []string{"Metadata"}
+
+	_, warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	// Any core region can be selected without candidates
+	delete(config, "regions")
+	b = Builder{}
+	if _, _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	for name, override := range map[string]map[string]any{
+		"regions without auto": {"region": "us-ord", "regions": []string{"us-mia"}},
+		"duplicate region":     {"regions": []string{"us-ord", "us-ord"}},
+		"auto in regions":      {"regions": []string{"auto"}},
+		"empty capability":     {"region_capabilities": []string{""}},
+		"existing volume":      {"volume": []map[string]any{{"volume_id": 123}}},
+		"existing group":       {"placement_group": map[string]any{"id": 123}},
+	} {
+		config := testConfig()
+		config["region"] = "auto"
+		for k, v := range override {
+			config[k] = v
+		}
+		b = Builder{}
+		if _, _, err := b.Prepare(config); err == nil {
+			t.Errorf("%s: should have error", name)
+		}
+	}
+}
+
+func TestBuilderPrepare_ImageShrink(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	config["image_shrink"] = true
+
+	_, warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if b.config.ImageShrinkMargin != 1024 {
+		t.Errorf("got %d, expected 1024", b.config.ImageShrinkMargin)
+	}
+
+	config["image_shrink_margin"] = 256
+	config["image_shrink_zero_free"] = true
+	b = Builder{}
+	if _, _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if b.config.ImageShrinkMargin != 256 {
+		t.Errorf("got %d, expected 256", b.config.ImageShrinkMargin)
+	}
+
+	// Negative margin
+	config["image_shrink_margin"] = -1
+	b = Builder{}
+	if _, _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Zeroing the free space is only done when shrinking
+	config = testConfig()
+	config["image_shrink_zero_free"] = true
+	b = Builder{}
+	if _, _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_Rescue(t *testing.T) {
+	rescueConfig := func() map[string]any {
+		return map[string]any{
+			"linode_token":  "bar",
+			"region":        "us-ord",
+			"instance_type": "g6-nanode-1",
+			"ssh_username":  "root",
+			"disk": []map[string]any{
+				{
+					"label":      "root",
+					"size":       4096,
+					"filesystem": "raw",
+				},
+			},
+			"config": []map[string]any{
+				{
+					"label":  "boot",
+					"kernel": "linode/grub2",
+					"devices": map[string]any{
+						"sda": map[string]any{
+							"disk_label": "root",
+						},
+					},
+				},
+			},
+			"rescue": map[string]any{
+				"lish_private_key_file": "lish_ed25519",
+				"lish_host_key":         "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAPmHycZT/LM2w98U6ag8IAlevceHpO79AMzYoFWWoEE",
+				"pre_mount_commands":    []string{"mkfs.ext4 -F $ROOT_DEVICE"},
+			},
+		}
+	}
+
+	var b Builder
+	_, warnings, err := b.Prepare(rescueConfig())
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if b.config.Rescue.MountPath != "/mnt/root" {
+		t.Errorf("got %q, expected /mnt/root", b.config.Rescue.MountPath)
+	}
+	if b.config.Rescue.LishHost != "lish-us-ord.linode.com" {
+		t.Errorf("got %q, expected lish-us-ord.linode.com", b.config.Rescue.LishHost)
+	}
+	if len(b.config.Rescue.PreMountCommands) != 1 {
+		t.Errorf("expected 1 pre-mount command, got %d", len(b.config.Rescue.PreMountCommands))
+	}
+
+	// Explicit values are kept
+	config := rescueConfig()
+	config["rescue"].(map[string]any)["mount_path"] = "/target"
+	config["rescue"].(map[string]any)["lish_host"] = "lish-newark.linode.com"
+	b = Builder{}
+	if _, _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if b.config.Rescue.MountPath != "/target" {
+		t.Errorf("got %q, expected /target", b.config.Rescue.MountPath)
+	}
+	if b.config.Rescue.LishHost != "lish-newark.linode.com" {
+		t.Errorf("got %q, expected lish-newark.linode.com", b.config.Rescue.LishHost)
+	}
+
+	// Requires the Lish private key
+	config = rescueConfig()
+	delete(config["rescue"].(map[string]any), "lish_private_key_file")
+	b = Builder{}
+	if _, _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Requires the Lish host key
+	config = rescueConfig()
+	delete(config["rescue"].(map[string]any), "lish_host_key")
+	b = Builder{}
+	if _, _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Only authorizes SSH keys
+	config = rescueConfig()
+	config["ssh_password"] = "hunter2"
+	b = Builder{}
+	if _, _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Requires custom disks
+	config = rescueConfig()
+	delete(config, "disk")
+	delete(config, "config")
+	config["image"] = "linode/debian12"
+	b = Builder{}
+	if _, _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Only sda through sdg can be attached in rescue mode
+	config = rescueConfig()
+	config["config"].([]map[string]any)[0]["devices"].(map[string]any)["sdh"] = map[string]any{
+		"disk_label": "root",
+	}
+	b = Builder{}
+	if _, _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	// The mount path cannot be the root of the rescue environment
+	config = rescueConfig()
+	config["rescue"].(map[string]any)["mount_path"] = "/"
+	b = Builder{}
+	if _, _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Finnix only authorizes the SSH key for root
+	config = rescueConfig()
+	config["ssh_username"] = "packer"
+	b = Builder{}
+	if _, _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
 func TestBuilderPrepare_StackScripts(t *testing.T) {
 	var b Builder
 	config := testConfig()
@@ -1045,6 +1688,210 @@ func TestBuilderPrepare_CloudInit(t *testing.T) {
 	}
 }
 
+func TestBuilderPrepare_TemporaryFirewall(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	delete(config, "firewall_id")
+	config["temporary_firewall"] = true
+	config["temporary_firewall_source_cidrs"] = []string{"203.0.113.0/24", "2001:db8::/64"}
+
+	if _, _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if !b.config.TemporaryFirewall {
+		t.Error("temporary_firewall should be set")
+	}
+
+	// The public IP addresses of this host are detected for IPv6-only builds
+	b = Builder{}
+	config = testConfig()
+	config["temporary_firewall"] = true
+	config["ssh_interface"] = "public_ipv6"
+	if _, _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	// Other SSH paths are allowed with explicit CIDRs
+	b = Builder{}
+	config["ssh_bastion_host"] = "bastion.example.com"
+	config["ssh_bastion_agent_auth"] = true
+	config["temporary_firewall_source_cidrs"] = []string{"198.51.100.0/24"}
+	if _, _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	tests := map[string]func(map[string]any){
+		"firewall_id": func(c map[string]any) { c["firewall_id"] = 123 },
+		"invalid cidr": func(c map[string]any) {
+			c["temporary_firewall_source_cidrs"] = []string{"203.0.113.7"}
+		},
+		"cidrs without temporary_firewall": func(c map[string]any) {
+			c["temporary_firewall"] = false
+		},
+		"default cidrs with ssh_bastion_host": func(c map[string]any) {
+			delete(c, "temporary_firewall_source_cidrs")
+			c["ssh_bastion_host"] = "bastion.example.com"
+			c["ssh_bastion_agent_auth"] = true
+		},
+		"default cidrs with ssh_interface private_ipv4": func(c map[string]any) {
+			delete(c, "temporary_firewall_source_cidrs")
+			c["private_ip"] = true
+			c["ssh_interface"] = "private_ipv4"
+		},
+	}
+
+	for name, modify := range tests {
+		config := testConfig()
+		delete(config, "firewall_id")
+		config["temporary_firewall"] = true
+		config["temporary_firewall_source_cidrs"] = []string{"203.0.113.0/24"}
+		modify(config)
+
+		b = Builder{}
+		if _, _, err := b.Prepare(config); err == nil {
+			t.Errorf("%s: should have error", name)
+		}
+	}
+}
+
+func TestBuilderPrepare_TemporaryVPC(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	config["instance_label"] = "my_build.2024"
+	config["temporary_vpc"] = map[string]any{}
+
+	if _, _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if b.config.TemporaryVPC.Label != "my-build-2024" {
+		t.Errorf("got label %q, expected my-build-2024", b.config.TemporaryVPC.Label)
+	}
+	if b.config.TemporaryVPC.SubnetCIDR != "10.0.0.0/24" {
+		t.Errorf("got subnet_cidr %q, expected 10.0.0.0/24", b.config.TemporaryVPC.SubnetCIDR)
+	}
+
+	tests := map[string]func(map[string]any){
+		"invalid label": func(c map[string]any) {
+			c["temporary_vpc"] = map[string]any{"label": "my--vpc"}
+		},
+		"invalid cidr": func(c map[string]any) {
+			c["temporary_vpc"] = map[string]any{"subnet_cidr": "10.0.0.1"}
+		},
+		"ipv6 cidr": func(c map[string]any) {
+			c["temporary_vpc"] = map[string]any{"subnet_cidr": "2001:db8::/64"}
+		},
+		"interface": func(c map[string]any) {
+			c["interface"] = []map[string]any{{"purpose": "public"}}
+		},
+		"linode_interface": func(c map[string]any) {
+			c["linode_interface"] = []map[string]any{{"public": map[string]any{}}}
+		},
+		"source_linode_id": func(c map[string]any) {
+			delete(c, "image")
+			delete(c, "authorized_keys")
+			c["source_linode_id"] = 123
+			c["ssh_password"] = "secret"
+		},
+	}
+
+	for name, modify := range tests {
+		config := testConfig()
+		config["temporary_vpc"] = map[string]any{}
+		modify(config)
+
+		b = Builder{}
+		if _, _, err := b.Prepare(config); err == nil {
+			t.Errorf("%s: should have error", name)
+		}
+	}
+}
+
+func TestBuilderPrepare_Cleanup(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	if _, _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if b.config.CleanupTimeout != 10*time.Minute {
+		t.Errorf("got cleanup_timeout %s, expected 10m", b.config.CleanupTimeout)
+	}
+	if b.config.LeakedResourcesFile != "linode_leaked_resources.json" {
+		t.Errorf("got leaked_resources_file %q", b.config.LeakedResourcesFile)
+	}
+
+	b = Builder{}
+	config["cleanup_timeout"] = "30m"
+	config["packer_build_name"] = "nightly"
+	if _, _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if b.config.CleanupTimeout != 30*time.Minute {
+		t.Errorf("got cleanup_timeout %s, expected 30m", b.config.CleanupTimeout)
+	}
+	if b.config.LeakedResourcesFile != "linode_nightly_leaked_resources.json" {
+		t.Errorf("got leaked_resources_file %q", b.config.LeakedResourcesFile)
+	}
+}
+
+func TestBuilderPrepare_ImageForceReplace(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	if _, _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if b.config.ImageForceReplace {
+		t.Error("image_force_replace should default to false")
+	}
+
+	b = Builder{}
+	config["image_force_replace"] = true
+	if _, _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if !b.config.ImageForceReplace {
+		t.Error("image_force_replace should be set")
+	}
+}
+
+func TestBuilderPrepare_ImageTags(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	expectedTags := []string{"team=platform", "os:ubuntu"}
+	config["image_tags"] = expectedTags
+
+	_, warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if !reflect.DeepEqual(b.config.ImageTags, expectedTags) {
+		t.Errorf("got %v, expected %v", b.config.ImageTags, expectedTags)
+	}
+
+	for _, tag := range []string{"ab", strings.Repeat("a", 51), "new\nline"} {
+		b = Builder{}
+		config = testConfig()
+		config["image_tags"] = []string{tag}
+		if _, _, err := b.Prepare(config); err == nil {
+			t.Errorf("%q: should have error", tag)
+		}
+	}
+
+	// The build marker tag would get the resources deleted by the sweeper
+	for _, key := range []string{"instance_tags", "image_tags"} {
+		b = Builder{}
+		config = testConfig()
+		config[key] = []string{BuildMarkerTag}
+		if _, _, err := b.Prepare(config); err == nil {
+			t.Errorf("%s: should have error", key)
+		}
+	}
+}
+
 func TestBuilderPrepare_MetadataTagsFirewallID(t *testing.T) {
 	var b Builder
 	config := testConfig()
@@ -1459,7 +2306,7 @@ func TestBuilderPrepare_CustomDisksValidation(t *testing.T) {
 		if err == nil {
 			t.Fatal("expected error when neither image nor disks specified")
 		}
-		if !strings.Contains(err.Error(), "either image or custom disks must be specified") {
+		if !strings.Contains(err.Error(), "either image, custom disks or source_linode_id must be specified") {
 			t.Fatalf("expected specific error message, got: %s", err)
 		}
 	})
@@ -0,0 +1,44 @@
+package linode
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// chrootCommunicator wraps the communicator of the rescue environment so
+// that commands run chrooted into, and files are transferred relative to,
+// the mounted root device.
+type chrootCommunicator struct {
+	root string
+	comm packersdk.Communicator
+}
+
+// chrootCommand returns the command to run the given command chrooted into root.
+func chrootCommand(root, command string) string {
+	return "chroot " + shellQuote(root) + " /bin/sh -c " + shellQuote(command)
+}
+
+func (c *chrootCommunicator) Start(ctx context.Context, cmd *packersdk.RemoteCmd) error {
+	cmd.Command = chrootCommand(c.root, cmd.Command)
+	return c.comm.Start(ctx, cmd)
+}
+
+func (c *chrootCommunicator) Upload(dst string, r io.Reader, fi *os.FileInfo) error {
+	return c.comm.Upload(path.Join(c.root, dst), r, fi)
+}
+
+func (c *chrootCommunicator) UploadDir(dst string, src string, exclude []string) error {
+	return c.comm.UploadDir(path.Join(c.root, dst), src, exclude)
+}
+
+func (c *chrootCommunicator) Download(src string, w io.Writer) error {
+	return c.comm.Download(path.Join(c.root, src), w)
+}
+
+func (c *chrootCommunicator) DownloadDir(src string, dst string, exclude []string) error {
+	return c.comm.DownloadDir(path.Join(c.root, src), dst, exclude)
+}
@@ -1,5 +1,5 @@
 //go:generate packer-sdc struct-markdown
-//go:generate packer-sdc mapstructure-to-hcl2 -type Config,Interface,InterfaceIPv4,Metadata,Disk,InstanceConfig,InstanceConfigDevice,InstanceConfigDevices,InstanceConfigHelpers
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config,Interface,InterfaceIPv4,Metadata,Disk,InstanceConfig,InstanceConfigDevice,InstanceConfigDevices,InstanceConfigHelpers,ImageDisk,Volume
 
 package linode
 
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
 	"github.com/hashicorp/packer-plugin-sdk/template/config"
 	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+	"github.com/linode/linodego"
 	"github.com/linode/packer-plugin-linode/helper"
 )
 
@@ -216,6 +218,36 @@ type InstanceConfig struct {
 	VirtMode string `mapstructure:"virt_mode" required:"false"`
 }
 
+// ImageDisk represents an additional disk to create an image from.
+type ImageDisk struct {
+	// The label of the disk block to create the image from.
+	DiskLabel string `mapstructure:"disk_label" required:"true"`
+
+	// The name of the resulting image. Defaults to `<image_label>-<disk_label>`.
+	ImageLabel string `mapstructure:"image_label" required:"false"`
+
+	// The description of the resulting image. Defaults to `image_description`.
+	Description string `mapstructure:"image_description" required:"false"`
+}
+
+// Volume represents a Block Storage volume attached to the Linode during the build.
+type Volume struct {
+	// The label of the volume to create. Required unless `volume_id` is set.
+	Label string `mapstructure:"label" required:"false"`
+
+	// The size (GB) of the volume to create. Required unless `volume_id` is set.
+	// The minimum size is 10.
+	Size int `mapstructure:"size" required:"false"`
+
+	// The ID of an existing volume in the build region to attach instead of
+	// creating a volume.
+	VolumeID int `mapstructure:"volume_id" required:"false"`
+
+	// Whether to delete the volume after it is detached during cleanup.
+	// Defaults to true for created volumes and false for existing volumes.
+	DeleteOnCleanup *bool `mapstructure:"delete_on_cleanup" required:"false"`
+}
+
 type VPCInterfaceAttributes struct {
 	// The ID of the VPC Subnet this interface references.
 	SubnetID *int `mapstructure:"subnet_id"`
@@ -263,9 +295,22 @@ type Config struct {
 	// regions, but there will be less delay when deploying from the region where the image
 	// was taken. See [regions](https://api.linode.com/v4/regions) for more information on
 	// the available regions. Examples are `us-east`, `us-central`, `us-west`, `ap-south`,
-	// `ca-east`, `ap-northeast`, `eu-central`, and `eu-west`.
+	// `ca-east`, `ap-northeast`, `eu-central`, and `eu-west`. Set to `auto` to
+	// select the region from `regions`, or from all core regions if `regions` is
+	// not set, falling back to the next region when the Linode cannot be created
+	// for lack of capacity.
 	Region string `mapstructure:"region" required:"true"`
 
+	// The candidate regions, in order, to build in when `region` is `auto`.
+	Regions []string `mapstructure:"regions" required:"false"`
+
+	// The capabilities the build region must have, e.g. `Metadata`, `VPCs` or
+	// `Disk Encryption`. See the `capabilities` of
+	// [regions](https://api.linode.com/v4/regions). When `region` is `auto`,
+	// regions lacking any of them are skipped, otherwise the build fails.
+	// `Disk Encryption` is implied by `disk_encryption = "enabled"`.
+	RegionCapabilities []string `mapstructure:"region_capabilities" required:"false"`
+
 	// Public SSH keys need to be appended to the Linode instance.
 	AuthorizedKeys []string `mapstructure:"authorized_keys" required:"false"`
 
@@ -278,10 +323,19 @@ type Config struct {
 	// `g6-highmem-16`, and `g6-dedicated-16`.
 	InstanceType string `mapstructure:"instance_type" required:"true"`
 
+	// Linode types to fall back to, in order, when `instance_type` cannot be
+	// created because it is sold out or out of capacity in the region.
+	// The build fails on any other error. The type the Linode was created
+	// with is recorded in the `linode_type` state of the artifact.
+	InstanceTypeFallbacks []string `mapstructure:"instance_type_fallbacks" required:"false"`
+
 	// The name assigned to the Linode Instance.
 	Label string `mapstructure:"instance_label" required:"false"`
 
-	// Tags to apply to the instance when it is created.
+	// Tags to apply to the instance when it is created. The instance, and the
+	// volumes deleted during cleanup, are also tagged `packer-linode-build`
+	// so that packer-linode-sweep can find them if the build cannot clean up.
+	// `packer-linode-build` itself cannot be used as a tag.
 	Tags []string `mapstructure:"instance_tags" required:"false"`
 
 	// An Image ID to deploy the Disk from. Official Linode Images start with `linode/`,
@@ -307,6 +361,14 @@ type Config struct {
 	// a private IPv4 address.
 	PrivateIP bool `mapstructure:"private_ip" required:"false"`
 
+	// The address Packer connects to over SSH. Valid values are `public_ipv4`,
+	// `public_ipv6`, `private_ipv4`, `vpc_ipv4` and `vlan`. `private_ipv4` requires
+	// `private_ip` to be enabled, `vpc_ipv4` requires a VPC interface and `vlan`
+	// requires a VLAN interface with an `ipam_address`. If not set, the public IPv4
+	// address is used, falling back to the public IPv6 address when the Linode has
+	// no public IPv4 address. Ignored when `ssh_host` is set.
+	SSHInterface string `mapstructure:"ssh_interface" required:"false"`
+
 	// The root password of the Linode instance for building the image. Please note that when
 	// you create a new Linode instance with an image, at least one of root_pass,
 	// authorized_keys, or authorized_users must be provided
@@ -324,6 +386,17 @@ type Config struct {
 	// (such as "running") before timing out. The default state timeout is "5m".
 	StateTimeout time.Duration `mapstructure:"state_timeout" required:"false"`
 
+	// The time to wait, as a duration string, for the Linode instance and its
+	// temporary placement group and VPC to be deleted during cleanup. Deletion
+	// is retried while the instance is busy, for example while it is being
+	// imaged. The default cleanup timeout is "10m".
+	CleanupTimeout time.Duration `mapstructure:"cleanup_timeout" required:"false"`
+
+	// The path of the JSON report listing the resources, such as instances and
+	// volumes, that could not be cleaned up. The report is only written when
+	// resources leaked. Defaults to `linode_<build name>_leaked_resources.json`.
+	LeakedResourcesFile string `mapstructure:"leaked_resources_file" required:"false"`
+
 	// This attribute is required only if the StackScript being deployed requires input data from
 	// the User for successful completion. See User Defined Fields (UDFs) for more details.
 	//
@@ -349,9 +422,57 @@ type Config struct {
 	// The ID of the Firewall to attach this Linode to upon creation.
 	FirewallID int `mapstructure:"firewall_id" required:"false"`
 
-	// The regions where the outcome image will be replicated to.
+	// Whether to create a temporary Cloud Firewall for the build, which only
+	// allows inbound SSH from `temporary_firewall_source_cidrs`. The firewall
+	// is attached to the Linode, or to its public and VPC `linode_interface`
+	// blocks, and deleted during cleanup. Cannot be used with `firewall_id`.
+	TemporaryFirewall bool `mapstructure:"temporary_firewall" required:"false"`
+
+	// The CIDRs SSH is allowed from by the temporary firewall. Defaults to the
+	// public IPv4 and IPv6 addresses of the host running Packer, as detected
+	// through https://api.ipify.org and https://api6.ipify.org. Required when
+	// SSH does not come straight from this host over a public interface, i.e.
+	// with `ssh_bastion_host`, `ssh_proxy_host`, or an `ssh_interface` other
+	// than `public_ipv4` and `public_ipv6`.
+	TemporaryFirewallSourceCIDRs []string `mapstructure:"temporary_firewall_source_cidrs" required:"false"`
+
+	// Whether the disks of the Linode are encrypted. Valid values are `enabled`
+	// and `disabled`. Defaults to the default of the region. Encryption is only
+	// available in regions with the `Disk Encryption` capability, and cannot
+	// be specified when using `source_linode_id`.
+	DiskEncryption string `mapstructure:"disk_encryption" required:"false"`
+
+	// The regions where the outcome image will be replicated to. The image is
+	// always kept in the build region as well.
 	ImageRegions []string `mapstructure:"image_regions" required:"false"`
 
+	// Whether to keep the images created by a failed or cancelled build for
+	// debugging. By default, they are removed from the image share groups
+	// they joined and deleted.
+	KeepFailedImage bool `mapstructure:"keep_failed_image" required:"false"`
+
+	// Whether to replace the existing private images labelled like the created
+	// images. Once the new images are available, the existing ones are removed
+	// from the `image_share_group_ids` share groups and deleted. Their IDs are
+	// recorded in the `replaced_image_ids` state of the artifact.
+	ImageForceReplace bool `mapstructure:"image_force_replace" required:"false"`
+
+	// Tags to apply to the created images, including the images of the
+	// `image_disks` blocks. Tags follow the same rules as `instance_tags`.
+	// While the build runs, images are also tagged `packer-linode-build`.
+	ImageTags []string `mapstructure:"image_tags" required:"false"`
+
+	// The time to wait, as a duration string, for the image to be replicated
+	// to all of the `image_regions`. Replicas are awaited in parallel. The
+	// default image replication timeout is "30m".
+	ImageReplicationTimeout time.Duration `mapstructure:"image_replication_timeout" required:"false"`
+
+	// What to do when the image could not be replicated to some of the
+	// `image_regions` in time. `fail` (the default) fails the build, `warn`
+	// reports the failed regions and `ignore` only logs them. With `warn` and
+	// `ignore`, the artifact records the regions the image is available in.
+	ImageReplicationFailure string `mapstructure:"image_replication_failure" required:"false"`
+
 	// Image Share Group IDs to add the newly created private image to
 	// immediately after image creation.
 	ImageShareGroupIDs []int `mapstructure:"image_share_group_ids" required:"false"`
@@ -370,6 +491,66 @@ type Config struct {
 	// you are responsible for creating all configuration profiles.
 	// See the `config` block documentation for available options.
 	InstanceConfigs []InstanceConfig `mapstructure:"config" required:"false"`
+
+	// The ID of an existing Linode to clone into a temporary build instance
+	// instead of deploying an image. The source Linode is never modified or
+	// deleted. Because no SSH key can be injected into a clone, `ssh_password`
+	// (or `root_pass`) or `ssh_private_key_file` must allow access to the clone.
+	// Conflicts with `image`, `disk` and `config`.
+	SourceLinodeID int `mapstructure:"source_linode_id" required:"false"`
+
+	// The IDs of the source Linode's disks to clone. Defaults to all disks.
+	// Only valid with `source_linode_id`.
+	SourceLinodeDiskIDs []int `mapstructure:"source_linode_disk_ids" required:"false"`
+
+	// The IDs of the source Linode's configuration profiles to clone. Defaults to
+	// all configuration profiles. Only valid with `source_linode_id`.
+	SourceLinodeConfigIDs []int `mapstructure:"source_linode_config_ids" required:"false"`
+
+	// Additional disks to create images from, one after another after the image
+	// of the boot disk. Each image is shared and replicated like the boot disk
+	// image, in parallel.
+	// Requires the `disk` blocks. See the `image_disks` block documentation
+	// for available options.
+	ImageDisks []ImageDisk `mapstructure:"image_disks" required:"false"`
+
+	// Block Storage volumes to attach to the Linode for the duration of the
+	// build, for example as package caches or scratch space. The volumes are
+	// detached before the Linode is deleted. See the `volume` block
+	// documentation for available options.
+	Volumes []Volume `mapstructure:"volume" required:"false"`
+
+	// The placement group to create the Linode in. Either assigns the Linode
+	// to an existing placement group, or creates a temporary placement group
+	// that is deleted after the build. See the `placement_group` block
+	// documentation for available options.
+	PlacementGroup *PlacementGroup `mapstructure:"placement_group" required:"false"`
+
+	// A VPC with a single subnet to create in the build region and delete
+	// after the Linode. The Linode is connected to the subnet through a VPC
+	// interface with a 1:1 NAT address, which is a `linode_interface` when
+	// `interface_generation` is `linode` and a legacy `interface` otherwise.
+	// Cannot be used with the `interface` and `linode_interface` blocks. See
+	// the `temporary_vpc` block documentation for available options.
+	TemporaryVPC *TemporaryVPC `mapstructure:"temporary_vpc" required:"false"`
+
+	// Whether to shrink the disk to be imaged to its used space plus
+	// `image_shrink_margin` before creating the image, reducing the size of the
+	// image. Only ext3 and ext4 disks are shrunk.
+	ImageShrink bool `mapstructure:"image_shrink" required:"false"`
+
+	// The free space (MB) to leave on the disk when `image_shrink` is enabled.
+	// Defaults to 1024.
+	ImageShrinkMargin int `mapstructure:"image_shrink_margin" required:"false"`
+
+	// Whether to fill the free space of the disk with zeros before measuring
+	// it when `image_shrink` is enabled, so that the image compresses better.
+	ImageShrinkZeroFree bool `mapstructure:"image_shrink_zero_free" required:"false"`
+
+	// Builds the image from Linode Rescue Mode (Finnix) instead of from the
+	// booted configuration profile. Requires the `disk` and `config` blocks.
+	// See [Rescue Mode Builds](#rescue-mode-builds) for more information.
+	Rescue *Rescue `mapstructure:"rescue" required:"false"`
 }
 
 // parseRootDevice extracts the device slot name from a root_device path.
@@ -573,6 +754,123 @@ func (c *Config) getBootDiskLabel() (string, error) {
 	return device.DiskLabel, nil
 }
 
+// validateImageDisks sets the defaults of the image_disks blocks and
+// validates that they reference distinct, imageable custom disks.
+func (c *Config) validateImageDisks() []error {
+	var errs []error
+
+	if len(c.Disks) == 0 {
+		return append(errs, errors.New("image_disks requires custom disks"))
+	}
+
+	bootDiskLabel, _ := c.getBootDiskLabel()
+	seen := make(map[string]bool)
+
+	for i := range c.ImageDisks {
+		d := &c.ImageDisks[i]
+
+		if d.ImageLabel == "" {
+			d.ImageLabel = fmt.Sprintf("%s-%s", c.ImageLabel, d.DiskLabel)
+		}
+		if d.Description == "" {
+			d.Description = c.Description
+		}
+
+		switch {
+		case d.DiskLabel == "":
+			errs = append(errs, errors.New("image_disks: disk_label is required"))
+			continue
+		case seen[d.DiskLabel]:
+			errs = append(errs, fmt.Errorf("image_disks: duplicate disk_label %q", d.DiskLabel))
+			continue
+		case d.DiskLabel == bootDiskLabel:
+			errs = append(errs, fmt.Errorf("image_disks: disk %q is the boot disk, which is always imaged", d.DiskLabel))
+			continue
+		}
+		seen[d.DiskLabel] = true
+
+		idx := slices.IndexFunc(c.Disks, func(disk Disk) bool { return disk.Label == d.DiskLabel })
+		if idx < 0 {
+			errs = append(errs, fmt.Errorf("image_disks: disk %q is not defined in the disk blocks", d.DiskLabel))
+		} else if c.Disks[idx].Filesystem == "swap" {
+			errs = append(errs, fmt.Errorf("image_disks: disk %q is a swap disk and cannot be imaged", d.DiskLabel))
+		}
+	}
+
+	return errs
+}
+
+// prepare sets the defaults of the volume block and validates it.
+func (v *Volume) prepare() []error {
+	var errs []error
+
+	if v.DeleteOnCleanup == nil {
+		v.DeleteOnCleanup = linodego.Pointer(v.VolumeID == 0)
+	}
+
+	if v.VolumeID != 0 {
+		if v.Label != "" || v.Size != 0 {
+			errs = append(errs, fmt.Errorf("volume %d: label and size cannot be specified with volume_id", v.VolumeID))
+		}
+		return errs
+	}
+
+	if v.Label == "" {
+		errs = append(errs, errors.New("volume: label is required unless volume_id is specified"))
+	}
+
+	if v.Size < 10 {
+		errs = append(errs, fmt.Errorf("volume %q: size must be at least 10 GB", v.Label))
+	}
+
+	return errs
+}
+
+// validateSourceLinode validates the options that conflict with cloning
+// the build instance from an existing Linode.
+func (c *Config) validateSourceLinode() []error {
+	var errs []error
+
+	conflicts := map[string]bool{
+		"image":              c.Image != "",
+		"disk":               len(c.Disks) > 0,
+		"config":             len(c.InstanceConfigs) > 0,
+		"authorized_keys":    len(c.AuthorizedKeys) > 0,
+		"authorized_users":   len(c.AuthorizedUsers) > 0,
+		"swap_size":          c.SwapSize != nil,
+		"boot_size":          c.BootSize != nil,
+		"kernel":             c.Kernel != "",
+		"stackscript_id":     c.StackScriptID > 0,
+		"stackscript_data":   len(c.StackScriptData) > 0,
+		"interface":          len(c.Interfaces) > 0,
+		"linode_interface":   len(c.LinodeInterfaces) > 0,
+		"firewall_id":        c.FirewallID != 0,
+		"temporary_firewall": c.TemporaryFirewall,
+		"temporary_vpc":      c.TemporaryVPC != nil,
+		"disk_encryption":    c.DiskEncryption != "",
+	}
+
+	keys := make([]string, 0, len(conflicts))
+	for k, conflict := range conflicts {
+		if conflict {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		errs = append(errs, fmt.Errorf("%s cannot be specified when using source_linode_id", k))
+	}
+
+	if c.Comm.SSHPassword == "" && c.Comm.SSHPrivateKeyFile == "" && !c.Comm.SSHAgentAuth {
+		errs = append(errs, errors.New(
+			"ssh_password, root_pass or ssh_private_key_file is required when using source_linode_id "+
+				"because no SSH key can be injected into a cloned Linode"))
+	}
+
+	return errs
+}
+
 func (c *Config) Prepare(raws ...any) ([]string, error) {
 	if err := config.Decode(c, &config.DecodeOpts{
 		Interpolate:        true,
@@ -590,15 +888,12 @@ func (c *Config) Prepare(raws ...any) ([]string, error) {
 
 	// Defaults
 
-	if c.PersonalAccessToken == "" {
-		// Default to environment variable for linode_token, if it exists
-		c.PersonalAccessToken = os.Getenv("LINODE_TOKEN")
-	}
-
 	if c.APICAPath == "" {
 		c.APICAPath = os.Getenv("LINODE_CA")
 	}
 
+	errs = packersdk.MultiErrorAppend(errs, c.LinodeCommon.Prepare()...)
+
 	if c.ImageLabel == "" {
 		if def, err := interpolate.Render("packer-{{timestamp}}", nil); err == nil {
 			c.ImageLabel = def
@@ -621,11 +916,37 @@ func (c *Config) Prepare(raws ...any) ([]string, error) {
 		c.StateTimeout = 5 * time.Minute
 	}
 
+	if c.CleanupTimeout == 0 {
+		// Default to 10 minute timeouts deleting the instance
+		c.CleanupTimeout = 10 * time.Minute
+	}
+
+	if c.LeakedResourcesFile == "" {
+		c.LeakedResourcesFile = "linode_leaked_resources.json"
+		if c.PackerBuildName != "" {
+			c.LeakedResourcesFile = fmt.Sprintf("linode_%s_leaked_resources.json", c.PackerBuildName)
+		}
+	}
+
 	if c.ImageCreateTimeout == 0 {
 		// Default to 10 minute timeouts waiting for image creation
 		c.ImageCreateTimeout = 10 * time.Minute
 	}
 
+	if c.ImageReplicationTimeout == 0 {
+		// Default to 30 minute timeouts waiting for image replication
+		c.ImageReplicationTimeout = 30 * time.Minute
+	}
+
+	if c.ImageReplicationFailure == "" {
+		c.ImageReplicationFailure = helper.ReplicationFailureFail
+	}
+
+	if c.ImageShrinkMargin == 0 {
+		// Default to leaving 1 GB of free space on shrunk disks
+		c.ImageShrinkMargin = 1024
+	}
+
 	if strings.TrimSpace(c.RootPass) != "" {
 		c.Comm.SSHPassword = c.RootPass
 	}
@@ -726,14 +1047,33 @@ func (c *Config) Prepare(raws ...any) ([]string, error) {
 			errs, errors.New("region is required"))
 	}
 
+	errs = packersdk.MultiErrorAppend(errs, c.validateRegions()...)
+
 	if c.InstanceType == "" {
 		errs = packersdk.MultiErrorAppend(
 			errs, errors.New("instance_type is required"))
 	}
 
-	if c.Image == "" && len(c.Disks) == 0 {
+	for i, instanceType := range c.InstanceTypeFallbacks {
+		if instanceType == "" {
+			errs = packersdk.MultiErrorAppend(
+				errs, fmt.Errorf("instance_type_fallbacks[%d] cannot be empty", i))
+		} else if slices.Contains(instanceTypes(c)[:i+1], instanceType) {
+			errs = packersdk.MultiErrorAppend(
+				errs, fmt.Errorf("instance_type_fallbacks[%d]: duplicate instance type %q", i, instanceType))
+		}
+	}
+
+	if c.Image == "" && len(c.Disks) == 0 && c.SourceLinodeID == 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("either image, custom disks or source_linode_id must be specified"))
+	}
+
+	if c.SourceLinodeID != 0 {
+		errs = packersdk.MultiErrorAppend(errs, c.validateSourceLinode()...)
+	} else if len(c.SourceLinodeDiskIDs) > 0 || len(c.SourceLinodeConfigIDs) > 0 {
 		errs = packersdk.MultiErrorAppend(
-			errs, errors.New("either image or custom disks must be specified"))
+			errs, errors.New("source_linode_disk_ids and source_linode_config_ids require source_linode_id"))
 	}
 
 	if c.Image != "" && len(c.Disks) > 0 {
@@ -829,6 +1169,74 @@ func (c *Config) Prepare(raws ...any) ([]string, error) {
 		}
 	}
 
+	if len(c.ImageDisks) > 0 {
+		errs = packersdk.MultiErrorAppend(errs, c.validateImageDisks()...)
+	}
+
+	for i := range c.Volumes {
+		errs = packersdk.MultiErrorAppend(errs, c.Volumes[i].prepare()...)
+	}
+
+	switch linodego.InstanceDiskEncryption(c.DiskEncryption) {
+	case "", linodego.InstanceDiskEncryptionEnabled, linodego.InstanceDiskEncryptionDisabled:
+	default:
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("disk_encryption must be either enabled or disabled"))
+	}
+
+	if c.PlacementGroup != nil {
+		errs = packersdk.MultiErrorAppend(errs, c.PlacementGroup.prepare(c.Label)...)
+	}
+
+	errs = packersdk.MultiErrorAppend(errs, c.validateTemporaryFirewall()...)
+	errs = packersdk.MultiErrorAppend(errs, c.validateTemporaryVPC()...)
+
+	if err := helper.ValidateReplicationFailure(c.ImageReplicationFailure); err != nil {
+		errs = packersdk.MultiErrorAppend(errs, err)
+	}
+
+	if c.ImageReplicationTimeout < 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("image_replication_timeout must not be negative"))
+	}
+
+	if c.ImageShrinkMargin < 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("image_shrink_margin must not be negative"))
+	}
+
+	if c.ImageShrinkZeroFree && !c.ImageShrink {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("image_shrink_zero_free requires image_shrink"))
+	}
+
+	if c.Rescue != nil {
+		errs = packersdk.MultiErrorAppend(errs, c.validateRescue()...)
+	}
+
+	if c.SSHInterface != "" && !slices.Contains(validSSHInterfaces, c.SSHInterface) {
+		errs = packersdk.MultiErrorAppend(
+			errs, fmt.Errorf("ssh_interface must be one of %s", strings.Join(validSSHInterfaces, ", ")))
+	}
+
+	switch c.SSHInterface {
+	case sshInterfacePrivateIPv4:
+		if !c.PrivateIP {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("private_ip must be enabled when ssh_interface is private_ipv4"))
+		}
+	case sshInterfaceVPCIPv4:
+		if len(c.vpcSubnetIDs()) == 0 && c.TemporaryVPC == nil {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("a VPC interface must be configured when ssh_interface is vpc_ipv4"))
+		}
+	case sshInterfaceVLAN:
+		if c.vlanIPAMAddress() == "" {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("a VLAN interface with an ipam_address must be configured when ssh_interface is vlan"))
+		}
+	}
+
 	if c.Tags == nil {
 		c.Tags = make([]string, 0)
 	}
@@ -840,6 +1248,23 @@ func (c *Config) Prepare(raws ...any) ([]string, error) {
 		}
 	}
 
+	for _, t := range c.ImageTags {
+		if !tagRe.MatchString(t) {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("invalid image tag: %s", t))
+		}
+	}
+
+	// Images and persistent volumes keeping the build marker would be
+	// deleted by packer-linode-sweep
+	if slices.Contains(c.Tags, BuildMarkerTag) {
+		errs = packersdk.MultiErrorAppend(
+			errs, fmt.Errorf("instance_tags must not contain the build marker tag %q", BuildMarkerTag))
+	}
+	if slices.Contains(c.ImageTags, BuildMarkerTag) {
+		errs = packersdk.MultiErrorAppend(
+			errs, fmt.Errorf("image_tags must not contain the build marker tag %q", BuildMarkerTag))
+	}
+
 	if errs != nil && len(errs.Errors) > 0 {
 		return nil, errs
 	}
@@ -10,93 +10,125 @@ import (
 // FlatConfig is an auto-generated flat version of Config.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatConfig struct {
-	PackerBuildName           *string               `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
-	PackerBuilderType         *string               `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
-	PackerCoreVersion         *string               `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
-	PackerDebug               *bool                 `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
-	PackerForce               *bool                 `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
-	PackerOnError             *string               `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
-	PackerUserVars            map[string]string     `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
-	PackerSensitiveVars       []string              `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
-	PersonalAccessToken       *string               `mapstructure:"linode_token" cty:"linode_token" hcl:"linode_token"`
-	APICAPath                 *string               `mapstructure:"api_ca_path" cty:"api_ca_path" hcl:"api_ca_path"`
-	Type                      *string               `mapstructure:"communicator" cty:"communicator" hcl:"communicator"`
-	PauseBeforeConnect        *string               `mapstructure:"pause_before_connecting" cty:"pause_before_connecting" hcl:"pause_before_connecting"`
-	SSHHost                   *string               `mapstructure:"ssh_host" cty:"ssh_host" hcl:"ssh_host"`
-	SSHPort                   *int                  `mapstructure:"ssh_port" cty:"ssh_port" hcl:"ssh_port"`
-	SSHUsername               *string               `mapstructure:"ssh_username" cty:"ssh_username" hcl:"ssh_username"`
-	SSHPassword               *string               `mapstructure:"ssh_password" cty:"ssh_password" hcl:"ssh_password"`
-	SSHKeyPairName            *string               `mapstructure:"ssh_keypair_name" undocumented:"true" cty:"ssh_keypair_name" hcl:"ssh_keypair_name"`
-	SSHTemporaryKeyPairName   *string               `mapstructure:"temporary_key_pair_name" undocumented:"true" cty:"temporary_key_pair_name" hcl:"temporary_key_pair_name"`
-	SSHTemporaryKeyPairType   *string               `mapstructure:"temporary_key_pair_type" cty:"temporary_key_pair_type" hcl:"temporary_key_pair_type"`
-	SSHTemporaryKeyPairBits   *int                  `mapstructure:"temporary_key_pair_bits" cty:"temporary_key_pair_bits" hcl:"temporary_key_pair_bits"`
-	SSHCiphers                []string              `mapstructure:"ssh_ciphers" cty:"ssh_ciphers" hcl:"ssh_ciphers"`
-	SSHClearAuthorizedKeys    *bool                 `mapstructure:"ssh_clear_authorized_keys" cty:"ssh_clear_authorized_keys" hcl:"ssh_clear_authorized_keys"`
-	SSHKEXAlgos               []string              `mapstructure:"ssh_key_exchange_algorithms" cty:"ssh_key_exchange_algorithms" hcl:"ssh_key_exchange_algorithms"`
-	SSHPrivateKeyFile         *string               `mapstructure:"ssh_private_key_file" undocumented:"true" cty:"ssh_private_key_file" hcl:"ssh_private_key_file"`
-	SSHCertificateFile        *string               `mapstructure:"ssh_certificate_file" cty:"ssh_certificate_file" hcl:"ssh_certificate_file"`
-	SSHPty                    *bool                 `mapstructure:"ssh_pty" cty:"ssh_pty" hcl:"ssh_pty"`
-	SSHTimeout                *string               `mapstructure:"ssh_timeout" cty:"ssh_timeout" hcl:"ssh_timeout"`
-	SSHWaitTimeout            *string               `mapstructure:"ssh_wait_timeout" undocumented:"true" cty:"ssh_wait_timeout" hcl:"ssh_wait_timeout"`
-	SSHAgentAuth              *bool                 `mapstructure:"ssh_agent_auth" undocumented:"true" cty:"ssh_agent_auth" hcl:"ssh_agent_auth"`
-	SSHDisableAgentForwarding *bool                 `mapstructure:"ssh_disable_agent_forwarding" cty:"ssh_disable_agent_forwarding" hcl:"ssh_disable_agent_forwarding"`
-	SSHHandshakeAttempts      *int                  `mapstructure:"ssh_handshake_attempts" cty:"ssh_handshake_attempts" hcl:"ssh_handshake_attempts"`
-	SSHBastionHost            *string               `mapstructure:"ssh_bastion_host" cty:"ssh_bastion_host" hcl:"ssh_bastion_host"`
-	SSHBastionPort            *int                  `mapstructure:"ssh_bastion_port" cty:"ssh_bastion_port" hcl:"ssh_bastion_port"`
-	SSHBastionAgentAuth       *bool                 `mapstructure:"ssh_bastion_agent_auth" cty:"ssh_bastion_agent_auth" hcl:"ssh_bastion_agent_auth"`
-	SSHBastionUsername        *string               `mapstructure:"ssh_bastion_username" cty:"ssh_bastion_username" hcl:"ssh_bastion_username"`
-	SSHBastionPassword        *string               `mapstructure:"ssh_bastion_password" cty:"ssh_bastion_password" hcl:"ssh_bastion_password"`
-	SSHBastionInteractive     *bool                 `mapstructure:"ssh_bastion_interactive" cty:"ssh_bastion_interactive" hcl:"ssh_bastion_interactive"`
-	SSHBastionPrivateKeyFile  *string               `mapstructure:"ssh_bastion_private_key_file" cty:"ssh_bastion_private_key_file" hcl:"ssh_bastion_private_key_file"`
-	SSHBastionCertificateFile *string               `mapstructure:"ssh_bastion_certificate_file" cty:"ssh_bastion_certificate_file" hcl:"ssh_bastion_certificate_file"`
-	SSHFileTransferMethod     *string               `mapstructure:"ssh_file_transfer_method" cty:"ssh_file_transfer_method" hcl:"ssh_file_transfer_method"`
-	SSHProxyHost              *string               `mapstructure:"ssh_proxy_host" cty:"ssh_proxy_host" hcl:"ssh_proxy_host"`
-	SSHProxyPort              *int                  `mapstructure:"ssh_proxy_port" cty:"ssh_proxy_port" hcl:"ssh_proxy_port"`
-	SSHProxyUsername          *string               `mapstructure:"ssh_proxy_username" cty:"ssh_proxy_username" hcl:"ssh_proxy_username"`
-	SSHProxyPassword          *string               `mapstructure:"ssh_proxy_password" cty:"ssh_proxy_password" hcl:"ssh_proxy_password"`
-	SSHKeepAliveInterval      *string               `mapstructure:"ssh_keep_alive_interval" cty:"ssh_keep_alive_interval" hcl:"ssh_keep_alive_interval"`
-	SSHReadWriteTimeout       *string               `mapstructure:"ssh_read_write_timeout" cty:"ssh_read_write_timeout" hcl:"ssh_read_write_timeout"`
-	SSHRemoteTunnels          []string              `mapstructure:"ssh_remote_tunnels" cty:"ssh_remote_tunnels" hcl:"ssh_remote_tunnels"`
-	SSHLocalTunnels           []string              `mapstructure:"ssh_local_tunnels" cty:"ssh_local_tunnels" hcl:"ssh_local_tunnels"`
-	SSHPublicKey              []byte                `mapstructure:"ssh_public_key" undocumented:"true" cty:"ssh_public_key" hcl:"ssh_public_key"`
-	SSHPrivateKey             []byte                `mapstructure:"ssh_private_key" undocumented:"true" cty:"ssh_private_key" hcl:"ssh_private_key"`
-	WinRMUser                 *string               `mapstructure:"winrm_username" cty:"winrm_username" hcl:"winrm_username"`
-	WinRMPassword             *string               `mapstructure:"winrm_password" cty:"winrm_password" hcl:"winrm_password"`
-	WinRMHost                 *string               `mapstructure:"winrm_host" cty:"winrm_host" hcl:"winrm_host"`
-	WinRMNoProxy              *bool                 `mapstructure:"winrm_no_proxy" cty:"winrm_no_proxy" hcl:"winrm_no_proxy"`
-	WinRMPort                 *int                  `mapstructure:"winrm_port" cty:"winrm_port" hcl:"winrm_port"`
-	WinRMTimeout              *string               `mapstructure:"winrm_timeout" cty:"winrm_timeout" hcl:"winrm_timeout"`
-	WinRMUseSSL               *bool                 `mapstructure:"winrm_use_ssl" cty:"winrm_use_ssl" hcl:"winrm_use_ssl"`
-	WinRMInsecure             *bool                 `mapstructure:"winrm_insecure" cty:"winrm_insecure" hcl:"winrm_insecure"`
-	WinRMUseNTLM              *bool                 `mapstructure:"winrm_use_ntlm" cty:"winrm_use_ntlm" hcl:"winrm_use_ntlm"`
-	Interfaces                []FlatInterface       `mapstructure:"interface" required:"false" cty:"interface" hcl:"interface"`
-	LinodeInterfaces          []FlatLinodeInterface `mapstructure:"linode_interface" required:"false" cty:"linode_interface" hcl:"linode_interface"`
-	Region                    *string               `mapstructure:"region" required:"true" cty:"region" hcl:"region"`
-	AuthorizedKeys            []string              `mapstructure:"authorized_keys" required:"false" cty:"authorized_keys" hcl:"authorized_keys"`
-	AuthorizedUsers           []string              `mapstructure:"authorized_users" required:"false" cty:"authorized_users" hcl:"authorized_users"`
-	InstanceType              *string               `mapstructure:"instance_type" required:"true" cty:"instance_type" hcl:"instance_type"`
-	Label                     *string               `mapstructure:"instance_label" required:"false" cty:"instance_label" hcl:"instance_label"`
-	Tags                      []string              `mapstructure:"instance_tags" required:"false" cty:"instance_tags" hcl:"instance_tags"`
-	Image                     *string               `mapstructure:"image" required:"false" cty:"image" hcl:"image"`
-	SwapSize                  *int                  `mapstructure:"swap_size" required:"false" cty:"swap_size" hcl:"swap_size"`
-	BootSize                  *int                  `mapstructure:"boot_size" required:"false" cty:"boot_size" hcl:"boot_size"`
-	Kernel                    *string               `mapstructure:"kernel" required:"false" cty:"kernel" hcl:"kernel"`
-	PrivateIP                 *bool                 `mapstructure:"private_ip" required:"false" cty:"private_ip" hcl:"private_ip"`
-	RootPass                  *string               `mapstructure:"root_pass" required:"false" cty:"root_pass" hcl:"root_pass"`
-	ImageLabel                *string               `mapstructure:"image_label" required:"false" cty:"image_label" hcl:"image_label"`
-	Description               *string               `mapstructure:"image_description" required:"false" cty:"image_description" hcl:"image_description"`
-	StateTimeout              *string               `mapstructure:"state_timeout" required:"false" cty:"state_timeout" hcl:"state_timeout"`
-	StackScriptData           map[string]string     `mapstructure:"stackscript_data" required:"false" cty:"stackscript_data" hcl:"stackscript_data"`
-	StackScriptID             *int                  `mapstructure:"stackscript_id" required:"false" cty:"stackscript_id" hcl:"stackscript_id"`
-	ImageCreateTimeout        *string               `mapstructure:"image_create_timeout" required:"false" cty:"image_create_timeout" hcl:"image_create_timeout"`
-	CloudInit                 *bool                 `mapstructure:"cloud_init" required:"false" cty:"cloud_init" hcl:"cloud_init"`
-	Metadata                  *FlatMetadata         `mapstructure:"metadata" required:"false" cty:"metadata" hcl:"metadata"`
-	FirewallID                *int                  `mapstructure:"firewall_id" required:"false" cty:"firewall_id" hcl:"firewall_id"`
-	ImageRegions              []string              `mapstructure:"image_regions" required:"false" cty:"image_regions" hcl:"image_regions"`
-	ImageShareGroupIDs        []int                 `mapstructure:"image_share_group_ids" required:"false" cty:"image_share_group_ids" hcl:"image_share_group_ids"`
-	InterfaceGeneration       *string               `mapstructure:"interface_generation" required:"false" cty:"interface_generation" hcl:"interface_generation"`
-	Disks                     []FlatDisk            `mapstructure:"disk" required:"false" cty:"disk" hcl:"disk"`
-	InstanceConfigs           []FlatInstanceConfig  `mapstructure:"config" required:"false" cty:"config" hcl:"config"`
+	PackerBuildName              *string               `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
+	PackerBuilderType            *string               `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
+	PackerCoreVersion            *string               `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
+	PackerDebug                  *bool                 `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
+	PackerForce                  *bool                 `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
+	PackerOnError                *string               `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
+	PackerUserVars               map[string]string     `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
+	PackerSensitiveVars          []string              `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
+	PersonalAccessToken          *string               `mapstructure:"linode_token" cty:"linode_token" hcl:"linode_token"`
+	TokenFile                    *string               `mapstructure:"linode_token_file" cty:"linode_token_file" hcl:"linode_token_file"`
+	ConfigPath                   *string               `mapstructure:"linode_config_path" cty:"linode_config_path" hcl:"linode_config_path"`
+	Profile                      *string               `mapstructure:"linode_profile" cty:"linode_profile" hcl:"linode_profile"`
+	APICAPath                    *string               `mapstructure:"api_ca_path" cty:"api_ca_path" hcl:"api_ca_path"`
+	APIURL                       *string               `mapstructure:"api_url" cty:"api_url" hcl:"api_url"`
+	APIVersion                   *string               `mapstructure:"api_version" cty:"api_version" hcl:"api_version"`
+	APIMaxRetries                *int                  `mapstructure:"api_max_retries" cty:"api_max_retries" hcl:"api_max_retries"`
+	APIRetryMaxWait              *string               `mapstructure:"api_retry_max_wait" cty:"api_retry_max_wait" hcl:"api_retry_max_wait"`
+	Type                         *string               `mapstructure:"communicator" cty:"communicator" hcl:"communicator"`
+	PauseBeforeConnect           *string               `mapstructure:"pause_before_connecting" cty:"pause_before_connecting" hcl:"pause_before_connecting"`
+	SSHHost                      *string               `mapstructure:"ssh_host" cty:"ssh_host" hcl:"ssh_host"`
+	SSHPort                      *int                  `mapstructure:"ssh_port" cty:"ssh_port" hcl:"ssh_port"`
+	SSHUsername                  *string               `mapstructure:"ssh_username" cty:"ssh_username" hcl:"ssh_username"`
+	SSHPassword                  *string               `mapstructure:"ssh_password" cty:"ssh_password" hcl:"ssh_password"`
+	SSHKeyPairName               *string               `mapstructure:"ssh_keypair_name" undocumented:"true" cty:"ssh_keypair_name" hcl:"ssh_keypair_name"`
+	SSHTemporaryKeyPairName      *string               `mapstructure:"temporary_key_pair_name" undocumented:"true" cty:"temporary_key_pair_name" hcl:"temporary_key_pair_name"`
+	SSHTemporaryKeyPairType      *string               `mapstructure:"temporary_key_pair_type" cty:"temporary_key_pair_type" hcl:"temporary_key_pair_type"`
+	SSHTemporaryKeyPairBits      *int                  `mapstructure:"temporary_key_pair_bits" cty:"temporary_key_pair_bits" hcl:"temporary_key_pair_bits"`
+	SSHCiphers                   []string              `mapstructure:"ssh_ciphers" cty:"ssh_ciphers" hcl:"ssh_ciphers"`
+	SSHClearAuthorizedKeys       *bool                 `mapstructure:"ssh_clear_authorized_keys" cty:"ssh_clear_authorized_keys" hcl:"ssh_clear_authorized_keys"`
+	SSHKEXAlgos                  []string              `mapstructure:"ssh_key_exchange_algorithms" cty:"ssh_key_exchange_algorithms" hcl:"ssh_key_exchange_algorithms"`
+	SSHPrivateKeyFile            *string               `mapstructure:"ssh_private_key_file" undocumented:"true" cty:"ssh_private_key_file" hcl:"ssh_private_key_file"`
+	SSHCertificateFile           *string               `mapstructure:"ssh_certificate_file" cty:"ssh_certificate_file" hcl:"ssh_certificate_file"`
+	SSHPty                       *bool                 `mapstructure:"ssh_pty" cty:"ssh_pty" hcl:"ssh_pty"`
+	SSHTimeout                   *string               `mapstructure:"ssh_timeout" cty:"ssh_timeout" hcl:"ssh_timeout"`
+	SSHWaitTimeout               *string               `mapstructure:"ssh_wait_timeout" undocumented:"true" cty:"ssh_wait_timeout" hcl:"ssh_wait_timeout"`
+	SSHAgentAuth                 *bool                 `mapstructure:"ssh_agent_auth" undocumented:"true" cty:"ssh_agent_auth" hcl:"ssh_agent_auth"`
+	SSHDisableAgentForwarding    *bool                 `mapstructure:"ssh_disable_agent_forwarding" cty:"ssh_disable_agent_forwarding" hcl:"ssh_disable_agent_forwarding"`
+	SSHHandshakeAttempts         *int                  `mapstructure:"ssh_handshake_attempts" cty:"ssh_handshake_attempts" hcl:"ssh_handshake_attempts"`
+	SSHBastionHost               *string               `mapstructure:"ssh_bastion_host" cty:"ssh_bastion_host" hcl:"ssh_bastion_host"`
+	SSHBastionPort               *int                  `mapstructure:"ssh_bastion_port" cty:"ssh_bastion_port" hcl:"ssh_bastion_port"`
+	SSHBastionAgentAuth          *bool                 `mapstructure:"ssh_bastion_agent_auth" cty:"ssh_bastion_agent_auth" hcl:"ssh_bastion_agent_auth"`
+	SSHBastionUsername           *string               `mapstructure:"ssh_bastion_username" cty:"ssh_bastion_username" hcl:"ssh_bastion_username"`
+	SSHBastionPassword           *string               `mapstructure:"ssh_bastion_password" cty:"ssh_bastion_password" hcl:"ssh_bastion_password"`
+	SSHBastionInteractive        *bool                 `mapstructure:"ssh_bastion_interactive" cty:"ssh_bastion_interactive" hcl:"ssh_bastion_interactive"`
+	SSHBastionPrivateKeyFile     *string               `mapstructure:"ssh_bastion_private_key_file" cty:"ssh_bastion_private_key_file" hcl:"ssh_bastion_private_key_file"`
+	SSHBastionCertificateFile    *string               `mapstructure:"ssh_bastion_certificate_file" cty:"ssh_bastion_certificate_file" hcl:"ssh_bastion_certificate_file"`
+	SSHFileTransferMethod        *string               `mapstructure:"ssh_file_transfer_method" cty:"ssh_file_transfer_method" hcl:"ssh_file_transfer_method"`
+	SSHProxyHost                 *string               `mapstructure:"ssh_proxy_host" cty:"ssh_proxy_host" hcl:"ssh_proxy_host"`
+	SSHProxyPort                 *int                  `mapstructure:"ssh_proxy_port" cty:"ssh_proxy_port" hcl:"ssh_proxy_port"`
+	SSHProxyUsername             *string               `mapstructure:"ssh_proxy_username" cty:"ssh_proxy_username" hcl:"ssh_proxy_username"`
+	SSHProxyPassword             *string               `mapstructure:"ssh_proxy_password" cty:"ssh_proxy_password" hcl:"ssh_proxy_password"`
+	SSHKeepAliveInterval         *string               `mapstructure:"ssh_keep_alive_interval" cty:"ssh_keep_alive_interval" hcl:"ssh_keep_alive_interval"`
+	SSHReadWriteTimeout          *string               `mapstructure:"ssh_read_write_timeout" cty:"ssh_read_write_timeout" hcl:"ssh_read_write_timeout"`
+	SSHRemoteTunnels             []string              `mapstructure:"ssh_remote_tunnels" cty:"ssh_remote_tunnels" hcl:"ssh_remote_tunnels"`
+	SSHLocalTunnels              []string              `mapstructure:"ssh_local_tunnels" cty:"ssh_local_tunnels" hcl:"ssh_local_tunnels"`
+	SSHPublicKey                 []byte                `mapstructure:"ssh_public_key" undocumented:"true" cty:"ssh_public_key" hcl:"ssh_public_key"`
+	SSHPrivateKey                []byte                `mapstructure:"ssh_private_key" undocumented:"true" cty:"ssh_private_key" hcl:"ssh_private_key"`
+	WinRMUser                    *string               `mapstructure:"winrm_username" cty:"winrm_username" hcl:"winrm_username"`
+	WinRMPassword                *string               `mapstructure:"winrm_password" cty:"winrm_password" hcl:"winrm_password"`
+	WinRMHost                    *string               `mapstructure:"winrm_host" cty:"winrm_host" hcl:"winrm_host"`
+	WinRMNoProxy                 *bool                 `mapstructure:"winrm_no_proxy" cty:"winrm_no_proxy" hcl:"winrm_no_proxy"`
+	WinRMPort                    *int                  `mapstructure:"winrm_port" cty:"winrm_port" hcl:"winrm_port"`
+	WinRMTimeout                 *string               `mapstructure:"winrm_timeout" cty:"winrm_timeout" hcl:"winrm_timeout"`
+	WinRMUseSSL                  *bool                 `mapstructure:"winrm_use_ssl" cty:"winrm_use_ssl" hcl:"winrm_use_ssl"`
+	WinRMInsecure                *bool                 `mapstructure:"winrm_insecure" cty:"winrm_insecure" hcl:"winrm_insecure"`
+	WinRMUseNTLM                 *bool                 `mapstructure:"winrm_use_ntlm" cty:"winrm_use_ntlm" hcl:"winrm_use_ntlm"`
+	Interfaces                   []FlatInterface       `mapstructure:"interface" required:"false" cty:"interface" hcl:"interface"`
+	LinodeInterfaces             []FlatLinodeInterface `mapstructure:"linode_interface" required:"false" cty:"linode_interface" hcl:"linode_interface"`
+	Region                       *string               `mapstructure:"region" required:"true" cty:"region" hcl:"region"`
+	Regions                      []string              `mapstructure:"regions" required:"false" cty:"regions" hcl:"regions"`
+	RegionCapabilities           []string              `mapstructure:"region_capabilities" required:"false" cty:"region_capabilities" hcl:"region_capabilities"`
+	AuthorizedKeys               []string              `mapstructure:"authorized_keys" required:"false" cty:"authorized_keys" hcl:"authorized_keys"`
+	AuthorizedUsers              []string              `mapstructure:"authorized_users" required:"false" cty:"authorized_users" hcl:"authorized_users"`
+	InstanceType                 *string               `mapstructure:"instance_type" required:"true" cty:"instance_type" hcl:"instance_type"`
+	InstanceTypeFallbacks        []string              `mapstructure:"instance_type_fallbacks" required:"false" cty:"instance_type_fallbacks" hcl:"instance_type_fallbacks"`
+	Label                        *string               `mapstructure:"instance_label" required:"false" cty:"instance_label" hcl:"instance_label"`
+	Tags                         []string              `mapstructure:"instance_tags" required:"false" cty:"instance_tags" hcl:"instance_tags"`
+	Image                        *string               `mapstructure:"image" required:"false" cty:"image" hcl:"image"`
+	SwapSize                     *int                  `mapstructure:"swap_size" required:"false" cty:"swap_size" hcl:"swap_size"`
+	BootSize                     *int                  `mapstructure:"boot_size" required:"false" cty:"boot_size" hcl:"boot_size"`
+	Kernel                       *string               `mapstructure:"kernel" required:"false" cty:"kernel" hcl:"kernel"`
+	PrivateIP                    *bool                 `mapstructure:"private_ip" required:"false" cty:"private_ip" hcl:"private_ip"`
+	SSHInterface                 *string               `mapstructure:"ssh_interface" required:"false" cty:"ssh_interface" hcl:"ssh_interface"`
+	RootPass                     *string               `mapstructure:"root_pass" required:"false" cty:"root_pass" hcl:"root_pass"`
+	ImageLabel                   *string               `mapstructure:"image_label" required:"false" cty:"image_label" hcl:"image_label"`
+	Description                  *string               `mapstructure:"image_description" required:"false" cty:"image_description" hcl:"image_description"`
+	StateTimeout                 *string               `mapstructure:"state_timeout" required:"false" cty:"state_timeout" hcl:"state_timeout"`
+	CleanupTimeout               *string               `mapstructure:"cleanup_timeout" required:"false" cty:"cleanup_timeout" hcl:"cleanup_timeout"`
+	LeakedResourcesFile          *string               `mapstructure:"leaked_resources_file" required:"false" cty:"leaked_resources_file" hcl:"leaked_resources_file"`
+	StackScriptData              map[string]string     `mapstructure:"stackscript_data" required:"false" cty:"stackscript_data" hcl:"stackscript_data"`
+	StackScriptID                *int                  `mapstructure:"stackscript_id" required:"false" cty:"stackscript_id" hcl:"stackscript_id"`
+	ImageCreateTimeout           *string               `mapstructure:"image_create_timeout" required:"false" cty:"image_create_timeout" hcl:"image_create_timeout"`
+	CloudInit                    *bool                 `mapstructure:"cloud_init" required:"false" cty:"cloud_init" hcl:"cloud_init"`
+	Metadata                     *FlatMetadata         `mapstructure:"metadata" required:"false" cty:"metadata" hcl:"metadata"`
+	FirewallID                   *int                  `mapstructure:"firewall_id" required:"false" cty:"firewall_id" hcl:"firewall_id"`
+	TemporaryFirewall            *bool                 `mapstructure:"temporary_firewall" required:"false" cty:"temporary_firewall" hcl:"temporary_firewall"`
+	TemporaryFirewallSourceCIDRs []string              `mapstructure:"temporary_firewall_source_cidrs" required:"false" cty:"temporary_firewall_source_cidrs" hcl:"temporary_firewall_source_cidrs"`
+	DiskEncryption               *string               `mapstructure:"disk_encryption" required:"false" cty:"disk_encryption" hcl:"disk_encryption"`
+	ImageRegions                 []string              `mapstructure:"image_regions" required:"false" cty:"image_regions" hcl:"image_regions"`
+	KeepFailedImage              *bool                 `mapstructure:"keep_failed_image" required:"false" cty:"keep_failed_image" hcl:"keep_failed_image"`
+	ImageForceReplace            *bool                 `mapstructure:"image_force_replace" required:"false" cty:"image_force_replace" hcl:"image_force_replace"`
+	ImageTags                    []string              `mapstructure:"image_tags" required:"false" cty:"image_tags" hcl:"image_tags"`
+	ImageReplicationTimeout      *string               `mapstructure:"image_replication_timeout" required:"false" cty:"image_replication_timeout" hcl:"image_replication_timeout"`
+	ImageReplicationFailure      *string               `mapstructure:"image_replication_failure" required:"false" cty:"image_replication_failure" hcl:"image_replication_failure"`
+	ImageShareGroupIDs           []int                 `mapstructure:"image_share_group_ids" required:"false" cty:"image_share_group_ids" hcl:"image_share_group_ids"`
+	InterfaceGeneration          *string               `mapstructure:"interface_generation" required:"false" cty:"interface_generation" hcl:"interface_generation"`
+	Disks                        []FlatDisk            `mapstructure:"disk" required:"false" cty:"disk" hcl:"disk"`
+	InstanceConfigs              []FlatInstanceConfig  `mapstructure:"config" required:"false" cty:"config" hcl:"config"`
+	SourceLinodeID               *int                  `mapstructure:"source_linode_id" required:"false" cty:"source_linode_id" hcl:"source_linode_id"`
+	SourceLinodeDiskIDs          []int                 `mapstructure:"source_linode_disk_ids" required:"false" cty:"source_linode_disk_ids" hcl:"source_linode_disk_ids"`
+	SourceLinodeConfigIDs        []int                 `mapstructure:"source_linode_config_ids" required:"false" cty:"source_linode_config_ids" hcl:"source_linode_config_ids"`
+	ImageDisks                   []FlatImageDisk       `mapstructure:"image_disks" required:"false" cty:"image_disks" hcl:"image_disks"`
+	Volumes                      []FlatVolume          `mapstructure:"volume" required:"false" cty:"volume" hcl:"volume"`
+	PlacementGroup               *FlatPlacementGroup   `mapstructure:"placement_group" required:"false" cty:"placement_group" hcl:"placement_group"`
+	TemporaryVPC                 *FlatTemporaryVPC     `mapstructure:"temporary_vpc" required:"false" cty:"temporary_vpc" hcl:"temporary_vpc"`
+	ImageShrink                  *bool                 `mapstructure:"image_shrink" required:"false" cty:"image_shrink" hcl:"image_shrink"`
+	ImageShrinkMargin            *int                  `mapstructure:"image_shrink_margin" required:"false" cty:"image_shrink_margin" hcl:"image_shrink_margin"`
+	ImageShrinkZeroFree          *bool                 `mapstructure:"image_shrink_zero_free" required:"false" cty:"image_shrink_zero_free" hcl:"image_shrink_zero_free"`
+	Rescue                       *FlatRescue           `mapstructure:"rescue" required:"false" cty:"rescue" hcl:"rescue"`
 }
 
 // FlatMapstructure returns a new FlatConfig.
@@ -111,93 +143,125 @@ func (*Config) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec }
 // The decoded values from this spec will then be applied to a FlatConfig.
 func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"packer_build_name":            &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
-		"packer_builder_type":          &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
-		"packer_core_version":          &hcldec.AttrSpec{Name: "packer_core_version", Type: cty.String, Required: false},
-		"packer_debug":                 &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
-		"packer_force":                 &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
-		"packer_on_error":              &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
-		"packer_user_variables":        &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
-		"packer_sensitive_variables":   &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
-		"linode_token":                 &hcldec.AttrSpec{Name: "linode_token", Type: cty.String, Required: false},
-		"api_ca_path":                  &hcldec.AttrSpec{Name: "api_ca_path", Type: cty.String, Required: false},
-		"communicator":                 &hcldec.AttrSpec{Name: "communicator", Type: cty.String, Required: false},
-		"pause_before_connecting":      &hcldec.AttrSpec{Name: "pause_before_connecting", Type: cty.String, Required: false},
-		"ssh_host":                     &hcldec.AttrSpec{Name: "ssh_host", Type: cty.String, Required: false},
-		"ssh_port":                     &hcldec.AttrSpec{Name: "ssh_port", Type: cty.Number, Required: false},
-		"ssh_username":                 &hcldec.AttrSpec{Name: "ssh_username", Type: cty.String, Required: false},
-		"ssh_password":                 &hcldec.AttrSpec{Name: "ssh_password", Type: cty.String, Required: false},
-		"ssh_keypair_name":             &hcldec.AttrSpec{Name: "ssh_keypair_name", Type: cty.String, Required: false},
-		"temporary_key_pair_name":      &hcldec.AttrSpec{Name: "temporary_key_pair_name", Type: cty.String, Required: false},
-		"temporary_key_pair_type":      &hcldec.AttrSpec{Name: "temporary_key_pair_type", Type: cty.String, Required: false},
-		"temporary_key_pair_bits":      &hcldec.AttrSpec{Name: "temporary_key_pair_bits", Type: cty.Number, Required: false},
-		"ssh_ciphers":                  &hcldec.AttrSpec{Name: "ssh_ciphers", Type: cty.List(cty.String), Required: false},
-		"ssh_clear_authorized_keys":    &hcldec.AttrSpec{Name: "ssh_clear_authorized_keys", Type: cty.Bool, Required: false},
-		"ssh_key_exchange_algorithms":  &hcldec.AttrSpec{Name: "ssh_key_exchange_algorithms", Type: cty.List(cty.String), Required: false},
-		"ssh_private_key_file":         &hcldec.AttrSpec{Name: "ssh_private_key_file", Type: cty.String, Required: false},
-		"ssh_certificate_file":         &hcldec.AttrSpec{Name: "ssh_certificate_file", Type: cty.String, Required: false},
-		"ssh_pty":                      &hcldec.AttrSpec{Name: "ssh_pty", Type: cty.Bool, Required: false},
-		"ssh_timeout":                  &hcldec.AttrSpec{Name: "ssh_timeout", Type: cty.String, Required: false},
-		"ssh_wait_timeout":             &hcldec.AttrSpec{Name: "ssh_wait_timeout", Type: cty.String, Required: false},
-		"ssh_agent_auth":               &hcldec.AttrSpec{Name: "ssh_agent_auth", Type: cty.Bool, Required: false},
-		"ssh_disable_agent_forwarding": &hcldec.AttrSpec{Name: "ssh_disable_agent_forwarding", Type: cty.Bool, Required: false},
-		"ssh_handshake_attempts":       &hcldec.AttrSpec{Name: "ssh_handshake_attempts", Type: cty.Number, Required: false},
-		"ssh_bastion_host":             &hcldec.AttrSpec{Name: "ssh_bastion_host", Type: cty.String, Required: false},
-		"ssh_bastion_port":             &hcldec.AttrSpec{Name: "ssh_bastion_port", Type: cty.Number, Required: false},
-		"ssh_bastion_agent_auth":       &hcldec.AttrSpec{Name: "ssh_bastion_agent_auth", Type: cty.Bool, Required: false},
-		"ssh_bastion_username":         &hcldec.AttrSpec{Name: "ssh_bastion_username", Type: cty.String, Required: false},
-		"ssh_bastion_password":         &hcldec.AttrSpec{Name: "ssh_bastion_password", Type: cty.String, Required: false},
-		"ssh_bastion_interactive":      &hcldec.AttrSpec{Name: "ssh_bastion_interactive", Type: cty.Bool, Required: false},
-		"ssh_bastion_private_key_file": &hcldec.AttrSpec{Name: "ssh_bastion_private_key_file", Type: cty.String, Required: false},
-		"ssh_bastion_certificate_file": &hcldec.AttrSpec{Name: "ssh_bastion_certificate_file", Type: cty.String, Required: false},
-		"ssh_file_transfer_method":     &hcldec.AttrSpec{Name: "ssh_file_transfer_method", Type: cty.String, Required: false},
-		"ssh_proxy_host":               &hcldec.AttrSpec{Name: "ssh_proxy_host", Type: cty.String, Required: false},
-		"ssh_proxy_port":               &hcldec.AttrSpec{Name: "ssh_proxy_port", Type: cty.Number, Required: false},
-		"ssh_proxy_username":           &hcldec.AttrSpec{Name: "ssh_proxy_username", Type: cty.String, Required: false},
-		"ssh_proxy_password":           &hcldec.AttrSpec{Name: "ssh_proxy_password", Type: cty.String, Required: false},
-		"ssh_keep_alive_interval":      &hcldec.AttrSpec{Name: "ssh_keep_alive_interval", Type: cty.String, Required: false},
-		"ssh_read_write_timeout":       &hcldec.AttrSpec{Name: "ssh_read_write_timeout", Type: cty.String, Required: false},
-		"ssh_remote_tunnels":           &hcldec.AttrSpec{Name: "ssh_remote_tunnels", Type: cty.List(cty.String), Required: false},
-		"ssh_local_tunnels":            &hcldec.AttrSpec{Name: "ssh_local_tunnels", Type: cty.List(cty.String), Required: false},
-		"ssh_public_key":               &hcldec.AttrSpec{Name: "ssh_public_key", Type: cty.List(cty.Number), Required: false},
-		"ssh_private_key":              &hcldec.AttrSpec{Name: "ssh_private_key", Type: cty.List(cty.Number), Required: false},
-		"winrm_username":               &hcldec.AttrSpec{Name: "winrm_username", Type: cty.String, Required: false},
-		"winrm_password":               &hcldec.AttrSpec{Name: "winrm_password", Type: cty.String, Required: false},
-		"winrm_host":                   &hcldec.AttrSpec{Name: "winrm_host", Type: cty.String, Required: false},
-		"winrm_no_proxy":               &hcldec.AttrSpec{Name: "winrm_no_proxy", Type: cty.Bool, Required: false},
-		"winrm_port":                   &hcldec.AttrSpec{Name: "winrm_port", Type: cty.Number, Required: false},
-		"winrm_timeout":                &hcldec.AttrSpec{Name: "winrm_timeout", Type: cty.String, Required: false},
-		"winrm_use_ssl":                &hcldec.AttrSpec{Name: "winrm_use_ssl", Type: cty.Bool, Required: false},
-		"winrm_insecure":               &hcldec.AttrSpec{Name: "winrm_insecure", Type: cty.Bool, Required: false},
-		"winrm_use_ntlm":               &hcldec.AttrSpec{Name: "winrm_use_ntlm", Type: cty.Bool, Required: false},
-		"interface":                    &hcldec.BlockListSpec{TypeName: "interface", Nested: hcldec.ObjectSpec((*FlatInterface)(nil).HCL2Spec())},
-		"linode_interface":             &hcldec.BlockListSpec{TypeName: "linode_interface", Nested: hcldec.ObjectSpec((*FlatLinodeInterface)(nil).HCL2Spec())},
-		"region":                       &hcldec.AttrSpec{Name: "region", Type: cty.String, Required: false},
-		"authorized_keys":              &hcldec.AttrSpec{Name: "authorized_keys", Type: cty.List(cty.String), Required: false},
-		"authorized_users":             &hcldec.AttrSpec{Name: "authorized_users", Type: cty.List(cty.String), Required: false},
-		"instance_type":                &hcldec.AttrSpec{Name: "instance_type", Type: cty.String, Required: false},
-		"instance_label":               &hcldec.AttrSpec{Name: "instance_label", Type: cty.String, Required: false},
-		"instance_tags":                &hcldec.AttrSpec{Name: "instance_tags", Type: cty.List(cty.String), Required: false},
-		"image":                        &hcldec.AttrSpec{Name: "image", Type: cty.String, Required: false},
-		"swap_size":                    &hcldec.AttrSpec{Name: "swap_size", Type: cty.Number, Required: false},
-		"boot_size":                    &hcldec.AttrSpec{Name: "boot_size", Type: cty.Number, Required: false},
-		"kernel":                       &hcldec.AttrSpec{Name: "kernel", Type: cty.String, Required: false},
-		"private_ip":                   &hcldec.AttrSpec{Name: "private_ip", Type: cty.Bool, Required: false},
-		"root_pass":                    &hcldec.AttrSpec{Name: "root_pass", Type: cty.String, Required: false},
-		"image_label":                  &hcldec.AttrSpec{Name: "image_label", Type: cty.String, Required: false},
-		"image_description":            &hcldec.AttrSpec{Name: "image_description", Type: cty.String, Required: false},
-		"state_timeout":                &hcldec.AttrSpec{Name: "state_timeout", Type: cty.String, Required: false},
-		"stackscript_data":             &hcldec.AttrSpec{Name: "stackscript_data", Type: cty.Map(cty.String), Required: false},
-		"stackscript_id":               &hcldec.AttrSpec{Name: "stackscript_id", Type: cty.Number, Required: false},
-		"image_create_timeout":         &hcldec.AttrSpec{Name: "image_create_timeout", Type: cty.String, Required: false},
-		"cloud_init":                   &hcldec.AttrSpec{Name: "cloud_init", Type: cty.Bool, Required: false},
-		"metadata":                     &hcldec.BlockSpec{TypeName: "metadata", Nested: hcldec.ObjectSpec((*FlatMetadata)(nil).HCL2Spec())},
-		"firewall_id":                  &hcldec.AttrSpec{Name: "firewall_id", Type: cty.Number, Required: false},
-		"image_regions":                &hcldec.AttrSpec{Name: "image_regions", Type: cty.List(cty.String), Required: false},
-		"image_share_group_ids":        &hcldec.AttrSpec{Name: "image_share_group_ids", Type: cty.List(cty.Number), Required: false},
-		"interface_generation":         &hcldec.AttrSpec{Name: "interface_generation", Type: cty.String, Required: false},
-		"disk":                         &hcldec.BlockListSpec{TypeName: "disk", Nested: hcldec.ObjectSpec((*FlatDisk)(nil).HCL2Spec())},
-		"config":                       &hcldec.BlockListSpec{TypeName: "config", Nested: hcldec.ObjectSpec((*FlatInstanceConfig)(nil).HCL2Spec())},
+		"packer_build_name":               &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
+		"packer_builder_type":             &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
+		"packer_core_version":             &hcldec.AttrSpec{Name: "packer_core_version", Type: cty.String, Required: false},
+		"packer_debug":                    &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
+		"packer_force":                    &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
+		"packer_on_error":                 &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
+		"packer_user_variables":           &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
+		"packer_sensitive_variables":      &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
+		"linode_token":                    &hcldec.AttrSpec{Name: "linode_token", Type: cty.String, Required: false},
+		"linode_token_file":               &hcldec.AttrSpec{Name: "linode_token_file", Type: cty.String, Required: false},
+		"linode_config_path":              &hcldec.AttrSpec{Name: "linode_config_path", Type: cty.String, Required: false},
+		"linode_profile":                  &hcldec.AttrSpec{Name: "linode_profile", Type: cty.String, Required: false},
+		"api_ca_path":                     &hcldec.AttrSpec{Name: "api_ca_path", Type: cty.String, Required: false},
+		"api_url":                         &hcldec.AttrSpec{Name: "api_url", Type: cty.String, Required: false},
+		"api_version":                     &hcldec.AttrSpec{Name: "api_version", Type: cty.String, Required: false},
+		"api_max_retries":                 &hcldec.AttrSpec{Name: "api_max_retries", Type: cty.Number, Required: false},
+		"api_retry_max_wait":              &hcldec.AttrSpec{Name: "api_retry_max_wait", Type: cty.String, Required: false},
+		"communicator":                    &hcldec.AttrSpec{Name: "communicator", Type: cty.String, Required: false},
+		"pause_before_connecting":         &hcldec.AttrSpec{Name: "pause_before_connecting", Type: cty.String, Required: false},
+		"ssh_host":                        &hcldec.AttrSpec{Name: "ssh_host", Type: cty.String, Required: false},
+		"ssh_port":                        &hcldec.AttrSpec{Name: "ssh_port", Type: cty.Number, Required: false},
+		"ssh_username":                    &hcldec.AttrSpec{Name: "ssh_username", Type: cty.String, Required: false},
+		"ssh_password":                    &hcldec.AttrSpec{Name: "ssh_password", Type: cty.String, Required: false},
+		"ssh_keypair_name":                &hcldec.AttrSpec{Name: "ssh_keypair_name", Type: cty.String, Required: false},
+		"temporary_key_pair_name":         &hcldec.AttrSpec{Name: "temporary_key_pair_name", Type: cty.String, Required: false},
+		"temporary_key_pair_type":         &hcldec.AttrSpec{Name: "temporary_key_pair_type", Type: cty.String, Required: false},
+		"temporary_key_pair_bits":         &hcldec.AttrSpec{Name: "temporary_key_pair_bits", Type: cty.Number, Required: false},
+		"ssh_ciphers":                     &hcldec.AttrSpec{Name: "ssh_ciphers", Type: cty.List(cty.String), Required: false},
+		"ssh_clear_authorized_keys":       &hcldec.AttrSpec{Name: "ssh_clear_authorized_keys", Type: cty.Bool, Required: false},
+		"ssh_key_exchange_algorithms":     &hcldec.AttrSpec{Name: "ssh_key_exchange_algorithms", Type: cty.List(cty.String), Required: false},
+		"ssh_private_key_file":            &hcldec.AttrSpec{Name: "ssh_private_key_file", Type: cty.String, Required: false},
+		"ssh_certificate_file":            &hcldec.AttrSpec{Name: "ssh_certificate_file", Type: cty.String, Required: false},
+		"ssh_pty":                         &hcldec.AttrSpec{Name: "ssh_pty", Type: cty.Bool, Required: false},
+		"ssh_timeout":                     &hcldec.AttrSpec{Name: "ssh_timeout", Type: cty.String, Required: false},
+		"ssh_wait_timeout":                &hcldec.AttrSpec{Name: "ssh_wait_timeout", Type: cty.String, Required: false},
+		"ssh_agent_auth":                  &hcldec.AttrSpec{Name: "ssh_agent_auth", Type: cty.Bool, Required: false},
+		"ssh_disable_agent_forwarding":    &hcldec.AttrSpec{Name: "ssh_disable_agent_forwarding", Type: cty.Bool, Required: false},
+		"ssh_handshake_attempts":          &hcldec.AttrSpec{Name: "ssh_handshake_attempts", Type: cty.Number, Required: false},
+		"ssh_bastion_host":                &hcldec.AttrSpec{Name: "ssh_bastion_host", Type: cty.String, Required: false},
+		"ssh_bastion_port":                &hcldec.AttrSpec{Name: "ssh_bastion_port", Type: cty.Number, Required: false},
+		"ssh_bastion_agent_auth":          &hcldec.AttrSpec{Name: "ssh_bastion_agent_auth", Type: cty.Bool, Required: false},
+		"ssh_bastion_username":            &hcldec.AttrSpec{Name: "ssh_bastion_username", Type: cty.String, Required: false},
+		"ssh_bastion_password":            &hcldec.AttrSpec{Name: "ssh_bastion_password", Type: cty.String, Required: false},
+		"ssh_bastion_interactive":         &hcldec.AttrSpec{Name: "ssh_bastion_interactive", Type: cty.Bool, Required: false},
+		"ssh_bastion_private_key_file":    &hcldec.AttrSpec{Name: "ssh_bastion_private_key_file", Type: cty.String, Required: false},
+		"ssh_bastion_certificate_file":    &hcldec.AttrSpec{Name: "ssh_bastion_certificate_file", Type: cty.String, Required: false},
+		"ssh_file_transfer_method":        &hcldec.AttrSpec{Name: "ssh_file_transfer_method", Type: cty.String, Required: false},
+		"ssh_proxy_host":                  &hcldec.AttrSpec{Name: "ssh_proxy_host", Type: cty.String, Required: false},
+		"ssh_proxy_port":                  &hcldec.AttrSpec{Name: "ssh_proxy_port", Type: cty.Number, Required: false},
+		"ssh_proxy_username":              &hcldec.AttrSpec{Name: "ssh_proxy_username", Type: cty.String, Required: false},
+		"ssh_proxy_password":              &hcldec.AttrSpec{Name: "ssh_proxy_password", Type: cty.String, Required: false},
+		"ssh_keep_alive_interval":         &hcldec.AttrSpec{Name: "ssh_keep_alive_interval", Type: cty.String, Required: false},
+		"ssh_read_write_timeout":          &hcldec.AttrSpec{Name: "ssh_read_write_timeout", Type: cty.String, Required: false},
+		"ssh_remote_tunnels":              &hcldec.AttrSpec{Name: "ssh_remote_tunnels", Type: cty.List(cty.String), Required: false},
+		"ssh_local_tunnels":               &hcldec.AttrSpec{Name: "ssh_local_tunnels", Type: cty.List(cty.String), Required: false},
+		"ssh_public_key":                  &hcldec.AttrSpec{Name: "ssh_public_key", Type: cty.List(cty.Number), Required: false},
+		"ssh_private_key":                 &hcldec.AttrSpec{Name: "ssh_private_key", Type: cty.List(cty.Number), Required: false},
+		"winrm_username":                  &hcldec.AttrSpec{Name: "winrm_username", Type: cty.String, Required: false},
+		"winrm_password":                  &hcldec.AttrSpec{Name: "winrm_password", Type: cty.String, Required: false},
+		"winrm_host":                      &hcldec.AttrSpec{Name: "winrm_host", Type: cty.String, Required: false},
+		"winrm_no_proxy":                  &hcldec.AttrSpec{Name: "winrm_no_proxy", Type: cty.Bool, Required: false},
+		"winrm_port":                      &hcldec.AttrSpec{Name: "winrm_port", Type: cty.Number, Required: false},
+		"winrm_timeout":                   &hcldec.AttrSpec{Name: "winrm_timeout", Type: cty.String, Required: false},
+		"winrm_use_ssl":                   &hcldec.AttrSpec{Name: "winrm_use_ssl", Type: cty.Bool, Required: false},
+		"winrm_insecure":                  &hcldec.AttrSpec{Name: "winrm_insecure", Type: cty.Bool, Required: false},
+		"winrm_use_ntlm":                  &hcldec.AttrSpec{Name: "winrm_use_ntlm", Type: cty.Bool, Required: false},
+		"interface":                       &hcldec.BlockListSpec{TypeName: "interface", Nested: hcldec.ObjectSpec((*FlatInterface)(nil).HCL2Spec())},
+		"linode_interface":                &hcldec.BlockListSpec{TypeName: "linode_interface", Nested: hcldec.ObjectSpec((*FlatLinodeInterface)(nil).HCL2Spec())},
+		"region":                          &hcldec.AttrSpec{Name: "region", Type: cty.String, Required: false},
+		"regions":                         &hcldec.AttrSpec{Name: "regions", Type: cty.List(cty.String), Required: false},
+		"region_capabilities":             &hcldec.AttrSpec{Name: "region_capabilities", Type: cty.List(cty.String), Required: false},
+		"authorized_keys":                 &hcldec.AttrSpec{Name: "authorized_keys", Type: cty.List(cty.String), Required: false},
+		"authorized_users":                &hcldec.AttrSpec{Name: "authorized_users", Type: cty.List(cty.String), Required: false},
+		"instance_type":                   &hcldec.AttrSpec{Name: "instance_type", Type: cty.String, Required: false},
+		"instance_type_fallbacks":         &hcldec.AttrSpec{Name: "instance_type_fallbacks", Type: cty.List(cty.String), Required: false},
+		"instance_label":                  &hcldec.AttrSpec{Name: "instance_label", Type: cty.String, Required: false},
+		"instance_tags":                   &hcldec.AttrSpec{Name: "instance_tags", Type: cty.List(cty.String), Required: false},
+		"image":                           &hcldec.AttrSpec{Name: "image", Type: cty.String, Required: false},
+		"swap_size":                       &hcldec.AttrSpec{Name: "swap_size", Type: cty.Number, Required: false},
+		"boot_size":                       &hcldec.AttrSpec{Name: "boot_size", Type: cty.Number, Required: false},
+		"kernel":                          &hcldec.AttrSpec{Name: "kernel", Type: cty.String, Required: false},
+		"private_ip":                      &hcldec.AttrSpec{Name: "private_ip", Type: cty.Bool, Required: false},
+		"ssh_interface":                   &hcldec.AttrSpec{Name: "ssh_interface", Type: cty.String, Required: false},
+		"root_pass":                       &hcldec.AttrSpec{Name: "root_pass", Type: cty.String, Required: false},
+		"image_label":                     &hcldec.AttrSpec{Name: "image_label", Type: cty.String, Required: false},
+		"image_description":               &hcldec.AttrSpec{Name: "image_description", Type: cty.String, Required: false},
+		"state_timeout":                   &hcldec.AttrSpec{Name: "state_timeout", Type: cty.String, Required: false},
+		"cleanup_timeout":                 &hcldec.AttrSpec{Name: "cleanup_timeout", Type: cty.String, Required: false},
+		"leaked_resources_file":           &hcldec.AttrSpec{Name: "leaked_resources_file", Type: cty.String, Required: false},
+		"stackscript_data":                &hcldec.AttrSpec{Name: "stackscript_data", Type: cty.Map(cty.String), Required: false},
+		"stackscript_id":                  &hcldec.AttrSpec{Name: "stackscript_id", Type: cty.Number, Required: false},
+		"image_create_timeout":            &hcldec.AttrSpec{Name: "image_create_timeout", Type: cty.String, Required: false},
+		"cloud_init":                      &hcldec.AttrSpec{Name: "cloud_init", Type: cty.Bool, Required: false},
+		"metadata":                        &hcldec.BlockSpec{TypeName: "metadata", Nested: hcldec.ObjectSpec((*FlatMetadata)(nil).HCL2Spec())},
+		"firewall_id":                     &hcldec.AttrSpec{Name: "firewall_id", Type: cty.Number, Required: false},
+		"temporary_firewall":              &hcldec.AttrSpec{Name: "temporary_firewall", Type: cty.Bool, Required: false},
+		"temporary_firewall_source_cidrs": &hcldec.AttrSpec{Name: "temporary_firewall_source_cidrs", Type: cty.List(cty.String), Required: false},
+		"disk_encryption":                 &hcldec.AttrSpec{Name: "disk_encryption", Type: cty.String, Required: false},
+		"image_regions":                   &hcldec.AttrSpec{Name: "image_regions", Type: cty.List(cty.String), Required: false},
+		"keep_failed_image":               &hcldec.AttrSpec{Name: "keep_failed_image", Type: cty.Bool, Required: false},
+		"image_force_replace":             &hcldec.AttrSpec{Name: "image_force_replace", Type: cty.Bool, Required: false},
+		"image_tags":                      &hcldec.AttrSpec{Name: "image_tags", Type: cty.List(cty.String), Required: false},
+		"image_replication_timeout":       &hcldec.AttrSpec{Name: "image_replication_timeout", Type: cty.String, Required: false},
+		"image_replication_failure":       &hcldec.AttrSpec{Name: "image_replication_failure", Type: cty.String, Required: false},
+		"image_share_group_ids":           &hcldec.AttrSpec{Name: "image_share_group_ids", Type: cty.List(cty.Number), Required: false},
+		"interface_generation":            &hcldec.AttrSpec{Name: "interface_generation", Type: cty.String, Required: false},
+		"disk":                            &hcldec.BlockListSpec{TypeName: "disk", Nested: hcldec.ObjectSpec((*FlatDisk)(nil).HCL2Spec())},
+		"config":                          &hcldec.BlockListSpec{TypeName: "config", Nested: hcldec.ObjectSpec((*FlatInstanceConfig)(nil).HCL2Spec())},
+		"source_linode_id":                &hcldec.AttrSpec{Name: "source_linode_id", Type: cty.Number, Required: false},
+		"source_linode_disk_ids":          &hcldec.AttrSpec{Name: "source_linode_disk_ids", Type: cty.List(cty.Number), Required: false},
+		"source_linode_config_ids":        &hcldec.AttrSpec{Name: "source_linode_config_ids", Type: cty.List(cty.Number), Required: false},
+		"image_disks":                     &hcldec.BlockListSpec{TypeName: "image_disks", Nested: hcldec.ObjectSpec((*FlatImageDisk)(nil).HCL2Spec())},
+		"volume":                          &hcldec.BlockListSpec{TypeName: "volume", Nested: hcldec.ObjectSpec((*FlatVolume)(nil).HCL2Spec())},
+		"placement_group":                 &hcldec.BlockSpec{TypeName: "placement_group", Nested: hcldec.ObjectSpec((*FlatPlacementGroup)(nil).HCL2Spec())},
+		"temporary_vpc":                   &hcldec.BlockSpec{TypeName: "temporary_vpc", Nested: hcldec.ObjectSpec((*FlatTemporaryVPC)(nil).HCL2Spec())},
+		"image_shrink":                    &hcldec.AttrSpec{Name: "image_shrink", Type: cty.Bool, Required: false},
+		"image_shrink_margin":             &hcldec.AttrSpec{Name: "image_shrink_margin", Type: cty.Number, Required: false},
+		"image_shrink_zero_free":          &hcldec.AttrSpec{Name: "image_shrink_zero_free", Type: cty.Bool, Required: false},
+		"rescue":                          &hcldec.BlockSpec{TypeName: "rescue", Nested: hcldec.ObjectSpec((*FlatRescue)(nil).HCL2Spec())},
 	}
 	return s
 }
@@ -241,6 +305,33 @@ func (*FlatDisk) HCL2Spec() map[string]hcldec.Spec {
 	return s
 }
 
+// FlatImageDisk is an auto-generated flat version of ImageDisk.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatImageDisk struct {
+	DiskLabel   *string `mapstructure:"disk_label" required:"true" cty:"disk_label" hcl:"disk_label"`
+	ImageLabel  *string `mapstructure:"image_label" required:"false" cty:"image_label" hcl:"image_label"`
+	Description *string `mapstructure:"image_description" required:"false" cty:"image_description" hcl:"image_description"`
+}
+
+// FlatMapstructure returns a new FlatImageDisk.
+// FlatImageDisk is an auto-generated flat version of ImageDisk.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*ImageDisk) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatImageDisk)
+}
+
+// HCL2Spec returns the hcl spec of a ImageDisk.
+// This spec is used by HCL to read the fields of ImageDisk.
+// The decoded values from this spec will then be applied to a FlatImageDisk.
+func (*FlatImageDisk) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"disk_label":        &hcldec.AttrSpec{Name: "disk_label", Type: cty.String, Required: false},
+		"image_label":       &hcldec.AttrSpec{Name: "image_label", Type: cty.String, Required: false},
+		"image_description": &hcldec.AttrSpec{Name: "image_description", Type: cty.String, Required: false},
+	}
+	return s
+}
+
 // FlatInstanceConfig is an auto-generated flat version of InstanceConfig.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatInstanceConfig struct {
@@ -573,3 +664,32 @@ func (*FlatMetadata) HCL2Spec() map[string]hcldec.Spec {
 	}
 	return s
 }
+
+// FlatVolume is an auto-generated flat version of Volume.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatVolume struct {
+	Label           *string `mapstructure:"label" required:"false" cty:"label" hcl:"label"`
+	Size            *int    `mapstructure:"size" required:"false" cty:"size" hcl:"size"`
+	VolumeID        *int    `mapstructure:"volume_id" required:"false" cty:"volume_id" hcl:"volume_id"`
+	DeleteOnCleanup *bool   `mapstructure:"delete_on_cleanup" required:"false" cty:"delete_on_cleanup" hcl:"delete_on_cleanup"`
+}
+
+// FlatMapstructure returns a new FlatVolume.
+// FlatVolume is an auto-generated flat version of Volume.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*Volume) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatVolume)
+}
+
+// HCL2Spec returns the hcl spec of a Volume.
+// This spec is used by HCL to read the fields of Volume.
+// The decoded values from this spec will then be applied to a FlatVolume.
+func (*FlatVolume) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"label":             &hcldec.AttrSpec{Name: "label", Type: cty.String, Required: false},
+		"size":              &hcldec.AttrSpec{Name: "size", Type: cty.Number, Required: false},
+		"volume_id":         &hcldec.AttrSpec{Name: "volume_id", Type: cty.Number, Required: false},
+		"delete_on_cleanup": &hcldec.AttrSpec{Name: "delete_on_cleanup", Type: cty.Bool, Required: false},
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+package linode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+)
+
+// capacityErrorMessages are the fragments of the API error messages returned
+// when an instance type is sold out in a region. Other unavailability errors,
+// such as an image or placement group missing from the region, are not
+// capacity errors, as falling back would not fix them.
+var capacityErrorMessages = []string{
+	"sold out",
+	"out of capacity",
+	"insufficient capacity",
+	"not enough capacity",
+}
+
+// instanceTypes returns the instance types to try in order: instance_type,
+// followed by instance_type_fallbacks.
+func instanceTypes(c *Config) []string {
+	return append([]string{c.InstanceType}, c.InstanceTypeFallbacks...)
+}
+
+// isCapacityError returns whether err indicates that the instance type is out
+// of capacity in the region, as opposed to an invalid request or an outage of
+// the API.
+func isCapacityError(err error) bool {
+	// Of the errors of several attempts, only the last one matters
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := joined.Unwrap()
+		return len(errs) > 0 && isCapacityError(errs[len(errs)-1])
+	}
+	if wrapped := errors.Unwrap(err); wrapped != nil {
+		return isCapacityError(wrapped)
+	}
+
+	var apiErr *linodego.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	if apiErr.Code != http.StatusBadRequest {
+		return false
+	}
+
+	message := strings.ToLower(apiErr.Message)
+	for _, fragment := range capacityErrorMessages {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// createWithFallbacks calls create with each instance type in turn until it
+// succeeds or fails with an error other than a capacity error, and returns
+// the created instance along with the instance type used.
+func createWithFallbacks(
+	ui packersdk.Ui,
+	types []string,
+	create func(instanceType string) (*linodego.Instance, error),
+) (*linodego.Instance, string, error) {
+	var errs []error
+
+	for i, instanceType := range types {
+		instance, err := create(instanceType)
+		if err == nil {
+			return instance, instanceType, nil
+		}
+
+		if len(types) > 1 {
+			err = fmt.Errorf("%s: %w", instanceType, err)
+		}
+		errs = append(errs, err)
+
+		if !isCapacityError(err) || i == len(types)-1 {
+			return nil, "", errors.Join(errs...)
+		}
+
+		ui.Say(fmt.Sprintf("Instance type %s is unavailable, falling back to %s...", instanceType, types[i+1]))
+	}
+
+	return nil, "", errors.New("no instance type to create the Linode with")
+}
@@ -0,0 +1,158 @@
+package linode
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+)
+
+func TestIsCapacityError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "sold out",
+			err:      &linodego.Error{Code: 400, Message: "[type] This plan is sold out in us-ord"},
+			expected: true,
+		},
+		{
+			name:     "out of capacity",
+			err:      &linodego.Error{Code: 400, Message: "[region] Region is out of capacity for this plan"},
+			expected: true,
+		},
+		{
+			name:     "region unavailable",
+			err:      &linodego.Error{Code: 400, Message: "[region] Region is currently unavailable"},
+			expected: false,
+		},
+		{
+			name:     "image not available",
+			err:      &linodego.Error{Code: 400, Message: "[image] Image is not available in this region"},
+			expected: false,
+		},
+		{
+			name:     "service unavailable",
+			err:      &linodego.Error{Code: 503, Message: "Service Unavailable"},
+			expected: false,
+		},
+		{
+			name:     "wrapped",
+			err:      fmt.Errorf("g6-standard-2: %w", &linodego.Error{Code: 400, Message: "sold out"}),
+			expected: true,
+		},
+		{
+			name: "last of several attempts",
+			err: errors.Join(
+				&linodego.Error{Code: 400, Message: "sold out"},
+				&linodego.Error{Code: 400, Message: "sold out"},
+			),
+			expected: true,
+		},
+		{
+			name: "attempts ending with an invalid request",
+			err: fmt.Errorf("us-ord: %w", errors.Join(
+				&linodego.Error{Code: 400, Message: "sold out"},
+				&linodego.Error{Code: 400, Message: "invalid label"},
+			)),
+			expected: false,
+		},
+		{
+			name:     "invalid request",
+			err:      &linodego.Error{Code: 400, Message: "[label] Label must be unique"},
+			expected: false,
+		},
+		{
+			name:     "unauthorized",
+			err:      &linodego.Error{Code: 401, Message: "Invalid Token"},
+			expected: false,
+		},
+		{
+			name:     "not an API error",
+			err:      errors.New("sold out"),
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isCapacityError(tt.err); got != tt.expected {
+				t.Errorf("got %t, expected %t", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCreateWithFallbacks(t *testing.T) {
+	soldOut := &linodego.Error{Code: 400, Message: "sold out"}
+	invalid := &linodego.Error{Code: 400, Message: "invalid label"}
+
+	tests := []struct {
+		name          string
+		errs          map[string]error
+		expectedType  string
+		expectedTries []string
+		wantErr       bool
+	}{
+		{
+			name:          "first type",
+			expectedType:  "g6-standard-2",
+			expectedTries: []string{"g6-standard-2"},
+		},
+		{
+			name:          "falls back on capacity errors",
+			errs:          map[string]error{"g6-standard-2": soldOut, "g7-standard-2": soldOut},
+			expectedType:  "g6-dedicated-2",
+			expectedTries: []string{"g6-standard-2", "g7-standard-2", "g6-dedicated-2"},
+		},
+		{
+			name:          "stops on other errors",
+			errs:          map[string]error{"g6-standard-2": invalid},
+			expectedTries: []string{"g6-standard-2"},
+			wantErr:       true,
+		},
+		{
+			name: "all types unavailable",
+			errs: map[string]error{
+				"g6-standard-2": soldOut, "g7-standard-2": soldOut, "g6-dedicated-2": soldOut,
+			},
+			expectedTries: []string{"g6-standard-2", "g7-standard-2", "g6-dedicated-2"},
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tries []string
+			instance, instanceType, err := createWithFallbacks(
+				packersdk.TestUi(t),
+				[]string{"g6-standard-2", "g7-standard-2", "g6-dedicated-2"},
+				func(instanceType string) (*linodego.Instance, error) {
+					tries = append(tries, instanceType)
+					if err := tt.errs[instanceType]; err != nil {
+						return nil, err
+					}
+					return &linodego.Instance{Type: instanceType}, nil
+				},
+			)
+
+			if !reflect.DeepEqual(tries, tt.expectedTries) {
+				t.Errorf("tried %v, expected %v", tries, tt.expectedTries)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, wantErr %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if instanceType != tt.expectedType || instance.Type != tt.expectedType {
+				t.Errorf("got %q, expected %q", instanceType, tt.expectedType)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package linode
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+)
+
+// leakedResource is a resource the build created but could not clean up.
+type leakedResource struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+	Error string `json:"error"`
+}
+
+func (r leakedResource) String() string {
+	if r.Label != "" {
+		return fmt.Sprintf("%s %s (%s)", r.Type, r.ID, r.Label)
+	}
+	return fmt.Sprintf("%s %s", r.Type, r.ID)
+}
+
+// leakedResources collects the resources the steps could not clean up. It is
+// available in state as leaked_resources and reported once the build ends.
+type leakedResources struct {
+	mu        sync.Mutex
+	resources []leakedResource
+}
+
+// recordLeak records a resource that could not be cleaned up.
+func recordLeak(state multistep.StateBag, resourceType string, id any, label string, err error) {
+	v, ok := state.GetOk("leaked_resources")
+	if !ok {
+		return
+	}
+
+	l := v.(*leakedResources)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.resources = append(l.resources, leakedResource{
+		Type:  resourceType,
+		ID:    fmt.Sprint(id),
+		Label: label,
+		Error: err.Error(),
+	})
+}
+
+// report writes the leaked resources as JSON to the given path and returns an
+// error listing them, or nil if every resource was cleaned up.
+func (l *leakedResources) report(path string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.resources) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(l.resources))
+	for i, r := range l.resources {
+		names[i] = r.String()
+	}
+	message := "the following resources could not be cleaned up: " + strings.Join(names, ", ")
+
+	data, err := json.MarshalIndent(l.resources, "", "  ")
+	if err == nil {
+		err = os.WriteFile(path, append(data, '\n'), 0o600)
+	}
+	if err != nil {
+		return fmt.Errorf("%s; failed to write the report to %s: %w", message, path, err)
+	}
+
+	return fmt.Errorf("%s; see %s", message, path)
+}
@@ -0,0 +1,57 @@
+package linode
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+)
+
+func TestLeakedResourcesReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leaks.json")
+
+	leaks := &leakedResources{}
+	if err := leaks.report(path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("no report should be written when nothing leaked")
+	}
+
+	state := new(multistep.BasicStateBag)
+	state.Put("leaked_resources", leaks)
+	recordLeak(state, "instance", 123, "packer-build", errors.New("Linode busy"))
+	recordLeak(state, "volume", 456, "", errors.New("timed out"))
+
+	err := leaks.report(path)
+	if err == nil {
+		t.Fatal("expected an error listing the leaked resources")
+	}
+	for _, s := range []string{"instance 123 (packer-build)", "volume 456", path} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("error %q should mention %q", err, s)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read the report: %s", err)
+	}
+	var resources []leakedResource
+	if err := json.Unmarshal(data, &resources); err != nil {
+		t.Fatalf("failed to decode the report: %s", err)
+	}
+
+	expected := []leakedResource{
+		{Type: "instance", ID: "123", Label: "packer-build", Error: "Linode busy"},
+		{Type: "volume", ID: "456", Error: "timed out"},
+	}
+	if !reflect.DeepEqual(resources, expected) {
+		t.Errorf("got %#v, expected %#v", resources, expected)
+	}
+}
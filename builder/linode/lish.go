@@ -0,0 +1,152 @@
+package linode
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// lishOutputLimit is the amount of console output kept to look for markers.
+const lishOutputLimit = 64 * 1024
+
+// lishConsole is an interactive session on the serial console of a Linode,
+// opened through the Lish console gateway.
+type lishConsole struct {
+	client  *ssh.Client
+	session *ssh.Session
+	stdin   io.WriteCloser
+	output  *lishOutput
+}
+
+// lishOutput keeps the tail of the console output.
+type lishOutput struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (o *lishOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.buf = append(o.buf, p...)
+	if len(o.buf) > lishOutputLimit {
+		o.buf = o.buf[len(o.buf)-lishOutputLimit:]
+	}
+	return len(p), nil
+}
+
+func (o *lishOutput) contains(s string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return bytes.Contains(o.buf, []byte(s))
+}
+
+// dialLish opens the console of the Linode with the given label through the
+// Lish gateway, authenticating with the private key file.
+func dialLish(r *Rescue, username, label string) (*lishConsole, error) {
+	keyBytes, err := os.ReadFile(filepath.Clean(r.LishPrivateKeyFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Lish private key: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Lish private key: %w", err)
+	}
+
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(r.LishHostKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Lish host key: %w", err)
+	}
+
+	client, err := ssh.Dial("tcp", net.JoinHostPort(r.LishHost, "22"), &ssh.ClientConfig{
+		User:            username,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         30 * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", r.LishHost, err)
+	}
+
+	console := &lishConsole{client: client, output: &lishOutput{}}
+	if err := console.start(label); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return console, nil
+}
+
+func (l *lishConsole) start(label string) error {
+	session, err := l.client.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to open Lish session: %w", err)
+	}
+
+	if err := session.RequestPty("vt100", 40, 200, ssh.TerminalModes{ssh.ECHO: 0}); err != nil {
+		session.Close()
+		return fmt.Errorf("failed to request a terminal from Lish: %w", err)
+	}
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return err
+	}
+	session.Stdout = l.output
+	session.Stderr = l.output
+
+	// Lish attaches to the console of the Linode named by the command.
+	if err := session.Start(label); err != nil {
+		session.Close()
+		return fmt.Errorf("failed to attach to the console of %s: %w", label, err)
+	}
+
+	l.session = session
+	l.stdin = stdin
+	return nil
+}
+
+// runUntil types the command on the console until the marker shows up in
+// the console output. The command is typed again every interval, as the
+// console may not be ready to take input yet, so it must be idempotent.
+func (l *lishConsole) runUntil(ctx context.Context, command, marker string, interval time.Duration) error {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	var typed time.Time
+	for {
+		if l.output.contains(marker) {
+			return nil
+		}
+
+		if time.Since(typed) >= interval {
+			if _, err := io.WriteString(l.stdin, "\r"+command+"\r"); err != nil {
+				return fmt.Errorf("failed to write to the Lish console: %w", err)
+			}
+			typed = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for the rescue environment: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *lishConsole) Close() error {
+	if l.session != nil {
+		l.session.Close()
+	}
+	return l.client.Close()
+}
@@ -0,0 +1,197 @@
+//go:generate packer-sdc struct-markdown
+//go:generate packer-sdc mapstructure-to-hcl2 -type PlacementGroup
+package linode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+)
+
+// placementGroupPollInterval is how often the temporary placement group is
+// polled for its members during cleanup.
+const placementGroupPollInterval = 5 * time.Second
+
+var (
+	validPlacementGroupTypes = []string{
+		"anti_affinity:local",
+		"affinity:local",
+	}
+	validPlacementGroupPolicies = []string{
+		string(linodego.PlacementGroupPolicyStrict),
+		string(linodego.PlacementGroupPolicyFlexible),
+	}
+)
+
+// PlacementGroup selects the placement group to create the Linode in.
+type PlacementGroup struct {
+	// The ID of an existing placement group to assign the Linode to.
+	ID int `mapstructure:"id" required:"false"`
+
+	// The label of an existing placement group in the build region to assign
+	// the Linode to. When `type` is set, the label of the placement group to
+	// create instead, which defaults to `instance_label`.
+	Label string `mapstructure:"label" required:"false"`
+
+	// The type of a temporary placement group to create for the build and
+	// delete during cleanup. Valid values are `anti_affinity:local` and
+	// `affinity:local`. Placement groups can neither be tagged nor dated, so
+	// packer-linode-sweep does not find the temporary placement groups of
+	// builds that could not clean up.
+	Type string `mapstructure:"type" required:"false"`
+
+	// The policy of the temporary placement group. Valid values are `strict`
+	// and `flexible`. Defaults to `strict`.
+	Policy string `mapstructure:"policy" required:"false"`
+
+	// Whether the Linode can only be assigned to the placement group if it
+	// complies with the placement group policy.
+	CompliantOnly *bool `mapstructure:"compliant_only" required:"false"`
+}
+
+// prepare sets the defaults of the placement_group block and validates it.
+func (p *PlacementGroup) prepare(instanceLabel string) []error {
+	var errs []error
+
+	set := 0
+	for _, isSet := range []bool{p.ID != 0, p.Label != "" && p.Type == "", p.Type != ""} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		errs = append(errs, errors.New("placement_group: exactly one of id, label or type must be specified"))
+	}
+
+	if p.Type == "" {
+		if p.Policy != "" {
+			errs = append(errs, errors.New("placement_group: policy can only be specified with type"))
+		}
+		return errs
+	}
+
+	if p.Label == "" {
+		p.Label = instanceLabel
+	}
+	if p.Policy == "" {
+		p.Policy = string(linodego.PlacementGroupPolicyStrict)
+	}
+
+	if !slices.Contains(validPlacementGroupTypes, p.Type) {
+		errs = append(errs, fmt.Errorf(
+			"placement_group: type must be one of %s", strings.Join(validPlacementGroupTypes, ", ")))
+	}
+	if !slices.Contains(validPlacementGroupPolicies, p.Policy) {
+		errs = append(errs, fmt.Errorf(
+			"placement_group: policy must be one of %s", strings.Join(validPlacementGroupPolicies, ", ")))
+	}
+
+	return errs
+}
+
+// findPlacementGroup returns the ID of the placement group with the given
+// label in the region.
+func findPlacementGroup(ctx context.Context, client *linodego.Client, label, region string) (int, error) {
+	filter := linodego.Filter{}
+	filter.AddField(linodego.Eq, "label", label)
+	filter.AddField(linodego.Eq, "region", region)
+
+	filterString, err := filter.MarshalJSON()
+	if err != nil {
+		return 0, err
+	}
+
+	groups, err := client.ListPlacementGroups(ctx, linodego.NewListOptions(0, string(filterString)))
+	if err != nil {
+		return 0, err
+	}
+
+	if len(groups) == 0 {
+		return 0, fmt.Errorf("no placement group with label %q found in region %s", label, region)
+	}
+	return groups[0].ID, nil
+}
+
+// placementGroupOptions returns the placement group to create the Linode in,
+// creating a temporary placement group if needed. The ID of the created
+// placement group is recorded so that it is deleted during cleanup.
+func (s *stepCreateLinode) placementGroupOptions(
+	ctx context.Context,
+	c *Config,
+	region string,
+) (*linodego.InstanceCreatePlacementGroupOptions, error) {
+	pg := c.PlacementGroup
+	if pg == nil {
+		return nil, nil
+	}
+
+	id := pg.ID
+	switch {
+	case pg.Type != "":
+		group, err := s.client.CreatePlacementGroup(ctx, linodego.PlacementGroupCreateOptions{
+			Label:                pg.Label,
+			Region:               region,
+			PlacementGroupType:   linodego.PlacementGroupType(pg.Type),
+			PlacementGroupPolicy: linodego.PlacementGroupPolicy(pg.Policy),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create placement group: %w", err)
+		}
+		s.placementGroupID = group.ID
+		id = group.ID
+	case pg.Label != "":
+		var err error
+		if id, err = findPlacementGroup(ctx, s.client, pg.Label, region); err != nil {
+			return nil, err
+		}
+	}
+
+	return &linodego.InstanceCreatePlacementGroupOptions{
+		ID:            id,
+		CompliantOnly: pg.CompliantOnly,
+	}, nil
+}
+
+// deletePlacementGroup deletes the temporary placement group once the deleted
+// Linode has left it, as placement groups with members cannot be deleted,
+// for at most cleanup_timeout.
+func (s *stepCreateLinode) deletePlacementGroup(ui packersdk.Ui, c *Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.CleanupTimeout)
+	defer cancel()
+
+	ui.Say(fmt.Sprintf("Deleting placement group %d...", s.placementGroupID))
+
+	ticker := time.NewTicker(placementGroupPollInterval)
+	defer ticker.Stop()
+
+	for {
+		group, err := s.client.GetPlacementGroup(ctx, s.placementGroupID)
+		if linodego.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get placement group %d: %w", s.placementGroupID, err)
+		}
+		if len(group.Members) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"failed to wait for placement group %d to be empty: %w", s.placementGroupID, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+
+	if err := s.client.DeletePlacementGroup(ctx, s.placementGroupID); err != nil && !linodego.IsNotFound(err) {
+		return fmt.Errorf("failed to delete placement group %d: %w", s.placementGroupID, err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// Code generated by "packer-sdc mapstructure-to-hcl2"; DO NOT EDIT.
+
+package linode
+
+import (
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// FlatPlacementGroup is an auto-generated flat version of PlacementGroup.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatPlacementGroup struct {
+	ID            *int    `mapstructure:"id" required:"false" cty:"id" hcl:"id"`
+	Label         *string `mapstructure:"label" required:"false" cty:"label" hcl:"label"`
+	Type          *string `mapstructure:"type" required:"false" cty:"type" hcl:"type"`
+	Policy        *string `mapstructure:"policy" required:"false" cty:"policy" hcl:"policy"`
+	CompliantOnly *bool   `mapstructure:"compliant_only" required:"false" cty:"compliant_only" hcl:"compliant_only"`
+}
+
+// FlatMapstructure returns a new FlatPlacementGroup.
+// FlatPlacementGroup is an auto-generated flat version of PlacementGroup.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*PlacementGroup) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatPlacementGroup)
+}
+
+// HCL2Spec returns the hcl spec of a PlacementGroup.
+// This spec is used by HCL to read the fields of PlacementGroup.
+// The decoded values from this spec will then be applied to a FlatPlacementGroup.
+func (*FlatPlacementGroup) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"id":             &hcldec.AttrSpec{Name: "id", Type: cty.Number, Required: false},
+		"label":          &hcldec.AttrSpec{Name: "label", Type: cty.String, Required: false},
+		"type":           &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
+		"policy":         &hcldec.AttrSpec{Name: "policy", Type: cty.String, Required: false},
+		"compliant_only": &hcldec.AttrSpec{Name: "compliant_only", Type: cty.Bool, Required: false},
+	}
+	return s
+}
@@ -0,0 +1,234 @@
+package linode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+)
+
+const (
+	// autoRegion is the region value that selects the build region from the
+	// regions candidates or, without candidates, from all core regions.
+	autoRegion = "auto"
+
+	regionStatusOK = "ok"
+	regionSiteCore = "core"
+)
+
+// validateRegions validates the region selection options.
+func (c *Config) validateRegions() []error {
+	var errs []error
+
+	if c.Region != autoRegion {
+		if len(c.Regions) > 0 {
+			errs = append(errs, errors.New("regions can only be specified when region is auto"))
+		}
+		return errs
+	}
+
+	// Existing resources tie the build to their own region
+	if c.PlacementGroup != nil && c.PlacementGroup.Type == "" {
+		errs = append(errs, errors.New("an existing placement_group cannot be used when region is auto"))
+	}
+	for _, v := range c.Volumes {
+		if v.VolumeID != 0 {
+			errs = append(errs, errors.New("an existing volume cannot be used when region is auto"))
+			break
+		}
+	}
+
+	for i, region := range c.Regions {
+		switch {
+		case region == "" || region == autoRegion:
+			errs = append(errs, fmt.Errorf("regions[%d] must be a region ID", i))
+		case slices.Contains(c.Regions[:i], region):
+			errs = append(errs, fmt.Errorf("regions[%d]: duplicate region %q", i, region))
+		}
+	}
+
+	for i, capability := range c.RegionCapabilities {
+		if capability == "" {
+			errs = append(errs, fmt.Errorf("region_capabilities[%d] cannot be empty", i))
+		}
+	}
+
+	return errs
+}
+
+// requiredCapabilities returns the capabilities a region needs to build in:
+// region_capabilities, along with the capabilities implied by other options.
+func requiredCapabilities(c *Config) []string {
+	capabilities := slices.Clone(c.RegionCapabilities)
+
+	if linodego.InstanceDiskEncryption(c.DiskEncryption) == linodego.InstanceDiskEncryptionEnabled &&
+		!slices.Contains(capabilities, linodego.CapabilityDiskEncryption) {
+		capabilities = append(capabilities, linodego.CapabilityDiskEncryption)
+	}
+
+	if c.TemporaryVPC != nil && !slices.Contains(capabilities, linodego.CapabilityVPCs) {
+		capabilities = append(capabilities, linodego.CapabilityVPCs)
+	}
+
+	return capabilities
+}
+
+// hasCapability returns whether the region has the capability. Distributed
+// regions provide disk encryption through a capability of their own.
+func hasCapability(region linodego.Region, capability string) bool {
+	if slices.Contains(region.Capabilities, capability) {
+		return true
+	}
+	return capability == linodego.CapabilityDiskEncryption &&
+		slices.Contains(region.Capabilities, linodego.CapabilityLADiskEncryption)
+}
+
+// missingCapabilities returns the required capabilities the region lacks.
+func missingCapabilities(region linodego.Region, required []string) []string {
+	var missing []string
+	for _, capability := range required {
+		if !hasCapability(region, capability) {
+			missing = append(missing, capability)
+		}
+	}
+	return missing
+}
+
+// filterRegions returns the candidate regions, in order, that are available
+// and have the required capabilities. When candidates is empty, every core
+// region is a candidate.
+func filterRegions(regions []linodego.Region, candidates, required []string) ([]string, error) {
+	byID := make(map[string]linodego.Region, len(regions))
+	for _, region := range regions {
+		byID[region.ID] = region
+	}
+
+	if len(candidates) == 0 {
+		for _, region := range regions {
+			if region.SiteType == regionSiteCore {
+				candidates = append(candidates, region.ID)
+			}
+		}
+	}
+
+	var selected []string
+	for _, id := range candidates {
+		region, ok := byID[id]
+		switch {
+		case !ok:
+			log.Printf("Skipping region %s: it does not exist", id)
+		case region.Status != regionStatusOK:
+			log.Printf("Skipping region %s: its status is %s", id, region.Status)
+		default:
+			if missing := missingCapabilities(region, required); len(missing) > 0 {
+				log.Printf("Skipping region %s: it lacks the %s capabilities", id, strings.Join(missing, ", "))
+				continue
+			}
+			selected = append(selected, id)
+		}
+	}
+
+	if len(selected) == 0 {
+		if len(required) > 0 {
+			return nil, fmt.Errorf("no available region has the %s capabilities", strings.Join(required, ", "))
+		}
+		return nil, errors.New("no available region to build in")
+	}
+	return selected, nil
+}
+
+// candidateRegions returns the regions to try to create the Linode in, in
+// order. A fixed region is only checked against the required capabilities,
+// on a best effort basis.
+func candidateRegions(ctx context.Context, client *linodego.Client, c *Config) ([]string, error) {
+	required := requiredCapabilities(c)
+
+	if c.Region != autoRegion {
+		if len(required) == 0 {
+			return []string{c.Region}, nil
+		}
+
+		region, err := client.GetRegion(ctx, c.Region)
+		if err != nil {
+			log.Printf("[WARN] Failed to get the capabilities of region %s: %s", c.Region, err)
+			return []string{c.Region}, nil
+		}
+
+		if missing := missingCapabilities(*region, required); len(missing) > 0 {
+			return nil, fmt.Errorf("region %s lacks the %s capabilities", c.Region, strings.Join(missing, ", "))
+		}
+		return []string{c.Region}, nil
+	}
+
+	regions, err := client.ListRegions(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list regions: %w", err)
+	}
+
+	return filterRegions(regions, c.Regions, required)
+}
+
+// createInRegions creates the Linode in each candidate region in turn, trying
+// every instance type in each region, until it succeeds or fails with an
+// error other than a capacity error. Temporary placement groups and VPCs of
+// the regions the Linode could not be created in are deleted right away, and
+// recorded as leaked when they cannot be.
+func (s *stepCreateLinode) createInRegions(
+	ctx context.Context,
+	state multistep.StateBag,
+	ui packersdk.Ui,
+	c *Config,
+	regions []string,
+	create func(region, instanceType string, pg *linodego.InstanceCreatePlacementGroupOptions) (*linodego.Instance, error),
+) (*linodego.Instance, string, error) {
+	var errs []error
+
+	for i, region := range regions {
+		pg, err := s.placementGroupOptions(ctx, c, region)
+		if err != nil {
+			return nil, "", errors.Join(append(errs, err)...)
+		}
+
+		instance, instanceType, err := createWithFallbacks(ui, instanceTypes(c), func(instanceType string) (*linodego.Instance, error) {
+			return create(region, instanceType, pg)
+		})
+		if err == nil {
+			return instance, instanceType, nil
+		}
+
+		if s.placementGroupID != 0 {
+			if err := s.deletePlacementGroup(ui, c); err != nil {
+				ui.Error("Error cleaning up placement group: " + err.Error())
+				recordLeak(state, "placement_group", s.placementGroupID, "", err)
+			}
+			s.placementGroupID = 0
+		}
+
+		if s.vpcID != 0 {
+			if err := s.deleteTemporaryVPC(ui, c); err != nil {
+				ui.Error("Error cleaning up VPC: " + err.Error())
+				recordLeak(state, "vpc", s.vpcID, c.TemporaryVPC.Label, err)
+			}
+			s.vpcID, s.subnetID = 0, 0
+		}
+
+		if len(regions) > 1 {
+			err = fmt.Errorf("%s: %w", region, err)
+		}
+		errs = append(errs, err)
+
+		if !isCapacityError(err) || i == len(regions)-1 {
+			return nil, "", errors.Join(errs...)
+		}
+
+		ui.Say(fmt.Sprintf("Region %s is unavailable, falling back to %s...", region, regions[i+1]))
+	}
+
+	return nil, "", errors.New("no region to create the Linode in")
+}
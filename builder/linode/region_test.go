@@ -0,0 +1,142 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+)
+
+func TestFilterRegions(t *testing.T) {
+	regions := []linodego.Region{
+		{ID: "us-ord", Status: "ok", SiteType: "core", Capabilities: []string{"Linodes", "Metadata", "Disk Encryption"}},
+		{ID: "us-east", Status: "outage", SiteType: "core", Capabilities: []string{"Linodes", "Metadata"}},
+		{ID: "us-mia", Status: "ok", SiteType: "core", Capabilities: []string{"Linodes"}},
+		{ID: "us-den-edge-1", Status: "ok", SiteType: "distributed", Capabilities: []string{"Linodes", "LA Disk Encryption"}},
+	}
+
+	tests := []struct {
+		name       string
+		candidates []string
+		required   []string
+		expected   []string
+		wantErr    bool
+	}{
+		{
+			name:     "all available core regions",
+			expected: []string{"us-ord", "us-mia"},
+		},
+		{
+			name:       "candidates in order",
+			candidates: []string{"us-mia", "us-east", "nowhere", "us-ord"},
+			expected:   []string{"us-mia", "us-ord"},
+		},
+		{
+			name:     "capabilities",
+			required: []string{"Metadata"},
+			expected: []string{"us-ord"},
+		},
+		{
+			name:       "distributed disk encryption",
+			candidates: []string{"us-den-edge-1", "us-mia"},
+			required:   []string{"Disk Encryption"},
+			expected:   []string{"us-den-edge-1"},
+		},
+		{
+			name:     "no region",
+			required: []string{"VPCs"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filterRegions(regions, tt.candidates, tt.required)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, wantErr %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("got %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRequiredCapabilities(t *testing.T) {
+	c := &Config{
+		RegionCapabilities: []string{"Metadata"},
+		DiskEncryption:     "enabled",
+	}
+
+	expected := []string{"Metadata", "Disk Encryption"}
+	if got := requiredCapabilities(c); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+	if !reflect.DeepEqual(c.RegionCapabilities, []string{"Metadata"}) {
+		t.Errorf("region_capabilities should not be modified, got %v", c.RegionCapabilities)
+	}
+}
+
+func TestCreateInRegions_RecordsLeaks(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		created int
+	)
+
+	// Temporary placement groups are created, but cannot be deleted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodPost && r.URL.Path == "/v4/placement/groups" {
+			mu.Lock()
+			created++
+			_, _ = fmt.Fprintf(w, `{"id": %d}`, created)
+			mu.Unlock()
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors": [{"reason": "Unauthorized"}]}`))
+	}))
+	defer server.Close()
+
+	c := &Config{
+		InstanceType:   "g6-nanode-1",
+		PlacementGroup: &PlacementGroup{Type: "anti_affinity:local", Label: "packer", Policy: "strict"},
+		CleanupTimeout: time.Second,
+		StateTimeout:   time.Second,
+	}
+	leaks := &leakedResources{}
+	state := new(multistep.BasicStateBag)
+	state.Put("leaked_resources", leaks)
+
+	step := &stepCreateLinode{client: newTestClient(t, server)}
+	_, _, err := step.createInRegions(
+		context.Background(), state, packersdk.TestUi(t), c, []string{"us-east", "us-ord"},
+		func(string, string, *linodego.InstanceCreatePlacementGroupOptions) (*linodego.Instance, error) {
+			return nil, &linodego.Error{Code: http.StatusBadRequest, Message: "This plan is sold out"}
+		})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	var ids []string
+	for _, r := range leaks.resources {
+		if r.Type == "placement_group" {
+			ids = append(ids, r.ID)
+		}
+	}
+	if !reflect.DeepEqual(ids, []string{"1", "2"}) {
+		t.Errorf("got leaked placement groups %v, expected [1 2]", ids)
+	}
+	if step.placementGroupID != 0 {
+		t.Errorf("got placement group ID %d, expected it to be reset", step.placementGroupID)
+	}
+}
@@ -0,0 +1,182 @@
+//go:generate packer-sdc struct-markdown
+//go:generate packer-sdc mapstructure-to-hcl2 -type Rescue
+package linode
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/packer-plugin-sdk/communicator"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// rescueReadyCommand prints rescueReadyMarker once the rescue environment
+	// has been prepared. The arithmetic keeps the marker out of the echoed
+	// command line, so only the command output can match it.
+	rescueReadyCommand = "echo packer-rescue-$((6*7))"
+	rescueReadyMarker  = "packer-rescue-42"
+
+	defaultRescueMountPath = "/mnt/root"
+)
+
+// rescueDeviceSlots are the device slots that can be attached to a Linode
+// booted into Rescue Mode. sdh is used by Finnix itself.
+var rescueDeviceSlots = []string{"sda", "sdb", "sdc", "sdd", "sde", "sdf", "sdg"}
+
+// Rescue configures building the image from the Linode Rescue Mode (Finnix)
+// environment instead of from a running distribution.
+type Rescue struct {
+	// The private key file used to authenticate with the Lish console gateway.
+	// The matching public key must be added to the Lish keys of the user
+	// profile. Lish is used to enable SSH in the rescue environment.
+	LishPrivateKeyFile string `mapstructure:"lish_private_key_file" required:"true"`
+
+	// The user to authenticate with the Lish console gateway as. Defaults to
+	// the username of the profile owning `linode_token`.
+	LishUsername string `mapstructure:"lish_username" required:"false"`
+
+	// The Lish console gateway to connect to. Defaults to
+	// `lish-<region>.linode.com`. Set this for regions whose gateway is not
+	// named after the region ID, e.g. `lish-newark.linode.com` for `us-east`.
+	LishHost string `mapstructure:"lish_host" required:"false"`
+
+	// The public host key of the Lish console gateway, in authorized_keys
+	// format, e.g. `ssh-ed25519 AAAA...`. The gateway is only trusted if it
+	// presents this key. Compare the key with the fingerprints Linode
+	// publishes for the gateway before using it.
+	LishHostKey string `mapstructure:"lish_host_key" required:"true"`
+
+	// The directory of the rescue environment to mount the root device of the
+	// boot configuration profile at. Provisioners run chrooted into this
+	// directory. Defaults to `/mnt/root`.
+	MountPath string `mapstructure:"mount_path" required:"false"`
+
+	// Commands to run in the rescue environment before the root device is
+	// mounted, for example to partition and format blank disks. The
+	// `ROOT_DEVICE` and `MOUNT_PATH` environment variables are set.
+	PreMountCommands []string `mapstructure:"pre_mount_commands" required:"false"`
+
+	// Commands to run in the rescue environment after the root device is
+	// mounted and before provisioning, for example to bootstrap a distribution
+	// into `MOUNT_PATH`. The `ROOT_DEVICE` and `MOUNT_PATH` environment
+	// variables are set.
+	PostMountCommands []string `mapstructure:"post_mount_commands" required:"false"`
+}
+
+// validateRescue sets the rescue defaults and validates the options of a
+// rescue mode build.
+func (c *Config) validateRescue() []error {
+	var errs []error
+
+	if c.Rescue.MountPath == "" {
+		c.Rescue.MountPath = defaultRescueMountPath
+	}
+
+	if c.Rescue.LishHost == "" && c.Region != "" && c.Region != autoRegion {
+		c.Rescue.LishHost = fmt.Sprintf("lish-%s.linode.com", c.Region)
+	}
+
+	if c.Rescue.LishPrivateKeyFile == "" {
+		errs = append(errs, errors.New("rescue.lish_private_key_file is required"))
+	}
+
+	if c.Rescue.LishHostKey == "" {
+		errs = append(errs, errors.New("rescue.lish_host_key is required to verify the Lish console gateway"))
+	} else if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(c.Rescue.LishHostKey)); err != nil {
+		errs = append(errs, fmt.Errorf("rescue.lish_host_key is invalid: %w", err))
+	}
+
+	// Secrets typed on the console would show up in its scrollback and logs,
+	// so only the SSH key of the communicator is authorized
+	if c.Comm.SSHPassword != "" {
+		errs = append(errs, errors.New(
+			"ssh_password and root_pass cannot be used with rescue, which only authorizes SSH keys"))
+	}
+
+	if !strings.HasPrefix(c.Rescue.MountPath, "/") || c.Rescue.MountPath == "/" {
+		errs = append(errs, errors.New("rescue.mount_path must be an absolute path other than /"))
+	}
+
+	if len(c.Disks) == 0 {
+		errs = append(errs, errors.New("disk and config blocks are required when using rescue"))
+	}
+
+	if c.Comm.SSHUsername != "" && c.Comm.SSHUsername != "root" {
+		errs = append(errs, errors.New("ssh_username must be root when using rescue"))
+	}
+
+	if bootConfig := c.getBootConfig(); bootConfig != nil {
+		for _, slot := range deviceSlotNames() {
+			if bootConfig.Devices.getDeviceAtSlot(slot) != nil && !slices.Contains(rescueDeviceSlots, slot) {
+				errs = append(errs, fmt.Errorf(
+					"device slot %s of config %q cannot be attached in rescue mode (only %s are supported)",
+					slot, bootConfig.Label, strings.Join(rescueDeviceSlots, ", ")))
+			}
+		}
+	}
+
+	return errs
+}
+
+// deviceSlotNames returns the names of all the device slots, sda through sdbl.
+func deviceSlotNames() []string {
+	var names []string
+	for _, prefix := range []string{"", "a", "b"} {
+		for c := 'a'; c <= 'z'; c++ {
+			names = append(names, "sd"+prefix+string(c))
+			if prefix == "b" && c == 'l' {
+				return names
+			}
+		}
+	}
+	return names
+}
+
+// rescuePublicKey returns the public key to authorize in the rescue
+// environment, in authorized_keys format.
+func rescuePublicKey(comm communicator.Config) (string, error) {
+	if len(comm.SSHPublicKey) > 0 {
+		return strings.TrimSpace(string(comm.SSHPublicKey)), nil
+	}
+
+	if len(comm.SSHPrivateKey) == 0 {
+		return "", nil
+	}
+
+	signer, err := ssh.ParsePrivateKey(comm.SSHPrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse SSH private key: %w", err)
+	}
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))), nil
+}
+
+// rescueSetupCommand returns the shell command run on the Lish console to
+// allow Packer to connect to the rescue environment over SSH with the given
+// public key. Only the public key is typed on the console.
+func rescueSetupCommand(publicKey string) string {
+	return strings.Join([]string{
+		"mkdir -p /root/.ssh",
+		"chmod 700 /root/.ssh",
+		fmt.Sprintf(
+			"(grep -qxF %[1]s /root/.ssh/authorized_keys 2>/dev/null || echo %[1]s >> /root/.ssh/authorized_keys)",
+			shellQuote(publicKey)),
+		"chmod 600 /root/.ssh/authorized_keys",
+		"(systemctl start ssh || service ssh start) >/dev/null 2>&1",
+		rescueReadyCommand,
+	}, "; ")
+}
+
+// rescueEnvCommand runs the command with a POSIX shell, with the ROOT_DEVICE
+// and MOUNT_PATH environment variables set.
+func rescueEnvCommand(rootDevice, mountPath, command string) string {
+	return fmt.Sprintf("ROOT_DEVICE=%s MOUNT_PATH=%s /bin/sh -c %s",
+		shellQuote(rootDevice), shellQuote(mountPath), shellQuote(command))
+}
+
+// shellQuote quotes the string for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
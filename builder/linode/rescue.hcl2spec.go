@@ -0,0 +1,43 @@
+// Code generated by "packer-sdc mapstructure-to-hcl2"; DO NOT EDIT.
+
+package linode
+
+import (
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// FlatRescue is an auto-generated flat version of Rescue.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatRescue struct {
+	LishPrivateKeyFile *string  `mapstructure:"lish_private_key_file" required:"true" cty:"lish_private_key_file" hcl:"lish_private_key_file"`
+	LishUsername       *string  `mapstructure:"lish_username" required:"false" cty:"lish_username" hcl:"lish_username"`
+	LishHost           *string  `mapstructure:"lish_host" required:"false" cty:"lish_host" hcl:"lish_host"`
+	LishHostKey        *string  `mapstructure:"lish_host_key" required:"true" cty:"lish_host_key" hcl:"lish_host_key"`
+	MountPath          *string  `mapstructure:"mount_path" required:"false" cty:"mount_path" hcl:"mount_path"`
+	PreMountCommands   []string `mapstructure:"pre_mount_commands" required:"false" cty:"pre_mount_commands" hcl:"pre_mount_commands"`
+	PostMountCommands  []string `mapstructure:"post_mount_commands" required:"false" cty:"post_mount_commands" hcl:"post_mount_commands"`
+}
+
+// FlatMapstructure returns a new FlatRescue.
+// FlatRescue is an auto-generated flat version of Rescue.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*Rescue) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatRescue)
+}
+
+// HCL2Spec returns the hcl spec of a Rescue.
+// This spec is used by HCL to read the fields of Rescue.
+// The decoded values from this spec will then be applied to a FlatRescue.
+func (*FlatRescue) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"lish_private_key_file": &hcldec.AttrSpec{Name: "lish_private_key_file", Type: cty.String, Required: false},
+		"lish_username":         &hcldec.AttrSpec{Name: "lish_username", Type: cty.String, Required: false},
+		"lish_host":             &hcldec.AttrSpec{Name: "lish_host", Type: cty.String, Required: false},
+		"lish_host_key":         &hcldec.AttrSpec{Name: "lish_host_key", Type: cty.String, Required: false},
+		"mount_path":            &hcldec.AttrSpec{Name: "mount_path", Type: cty.String, Required: false},
+		"pre_mount_commands":    &hcldec.AttrSpec{Name: "pre_mount_commands", Type: cty.List(cty.String), Required: false},
+		"post_mount_commands":   &hcldec.AttrSpec{Name: "post_mount_commands", Type: cty.List(cty.String), Required: false},
+	}
+	return s
+}
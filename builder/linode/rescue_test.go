@@ -0,0 +1,99 @@
+package linode
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"":              "''",
+		"/mnt/root":     "'/mnt/root'",
+		"it's":          `'it'\''s'`,
+		"$MOUNT_PATH":   "'$MOUNT_PATH'",
+		"a b; rm -rf /": "'a b; rm -rf /'",
+	}
+
+	for in, expected := range tests {
+		if got := shellQuote(in); got != expected {
+			t.Errorf("shellQuote(%q) = %q, expected %q", in, got, expected)
+		}
+	}
+}
+
+func TestRescueSetupCommand(t *testing.T) {
+	command := rescueSetupCommand("ssh-ed25519 AAAA packer")
+
+	for _, expected := range []string{
+		"echo 'ssh-ed25519 AAAA packer' >> /root/.ssh/authorized_keys",
+		"systemctl start ssh",
+	} {
+		if !strings.Contains(command, expected) {
+			t.Errorf("expected %q to contain %q", command, expected)
+		}
+	}
+
+	if strings.Contains(command, "chpasswd") {
+		t.Errorf("expected %q not to set a password", command)
+	}
+
+	if !strings.HasSuffix(command, rescueReadyCommand) {
+		t.Errorf("expected %q to end with %q", command, rescueReadyCommand)
+	}
+
+	// The echoed command line must not match the marker
+	if strings.Contains(command, rescueReadyMarker) {
+		t.Errorf("expected %q not to contain the marker %q", command, rescueReadyMarker)
+	}
+}
+
+func TestDeviceSlotNames(t *testing.T) {
+	names := deviceSlotNames()
+
+	if len(names) != 64 {
+		t.Fatalf("expected 64 slots, got %d", len(names))
+	}
+
+	if names[0] != "sda" || names[26] != "sdaa" || names[len(names)-1] != "sdbl" {
+		t.Errorf("unexpected slot names: %v", names)
+	}
+}
+
+func TestChrootCommunicator(t *testing.T) {
+	mock := &packersdk.MockCommunicator{}
+	comm := &chrootCommunicator{root: "/mnt/root", comm: mock}
+
+	cmd := &packersdk.RemoteCmd{Command: "echo 'hello'"}
+	if err := comm.Start(context.Background(), cmd); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	expected := `chroot '/mnt/root' /bin/sh -c 'echo '\''hello'\'''`
+	if mock.StartCmd.Command != expected {
+		t.Errorf("got %q, expected %q", mock.StartCmd.Command, expected)
+	}
+
+	if err := comm.Upload("/tmp/script.sh", strings.NewReader("true"), nil); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if mock.UploadPath != "/mnt/root/tmp/script.sh" {
+		t.Errorf("got %q, expected /mnt/root/tmp/script.sh", mock.UploadPath)
+	}
+
+	if err := comm.UploadDir("/etc/app", "files/", nil); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if mock.UploadDirDst != "/mnt/root/etc/app" {
+		t.Errorf("got %q, expected /mnt/root/etc/app", mock.UploadDirDst)
+	}
+
+	if err := comm.DownloadDir("/var/log", "logs/", nil); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if mock.DownloadDirSrc != "/mnt/root/var/log" {
+		t.Errorf("got %q, expected /mnt/root/var/log", mock.DownloadDirSrc)
+	}
+}
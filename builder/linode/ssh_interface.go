@@ -0,0 +1,179 @@
+package linode
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/linode/linodego"
+)
+
+// Valid values for the ssh_interface option.
+const (
+	sshInterfacePublicIPv4  = "public_ipv4"
+	sshInterfacePublicIPv6  = "public_ipv6"
+	sshInterfacePrivateIPv4 = "private_ipv4"
+	sshInterfaceVPCIPv4     = "vpc_ipv4"
+	sshInterfaceVLAN        = "vlan"
+)
+
+var validSSHInterfaces = []string{
+	sshInterfacePublicIPv4,
+	sshInterfacePublicIPv6,
+	sshInterfacePrivateIPv4,
+	sshInterfaceVPCIPv4,
+	sshInterfaceVLAN,
+}
+
+// legacyInterfaces returns all legacy config interfaces, including the ones
+// defined in custom configuration profiles.
+func (c *Config) legacyInterfaces() []Interface {
+	result := slices.Clone(c.Interfaces)
+	for _, cfg := range c.InstanceConfigs {
+		result = append(result, cfg.Interfaces...)
+	}
+	return result
+}
+
+// vpcSubnetIDs returns the IDs of the VPC subnets referenced by the
+// interface and linode_interface blocks.
+func (c *Config) vpcSubnetIDs() []int {
+	var result []int
+
+	for _, i := range c.legacyInterfaces() {
+		if i.Purpose == "vpc" && i.SubnetID != nil {
+			result = append(result, *i.SubnetID)
+		}
+	}
+
+	for _, li := range c.LinodeInterfaces {
+		if li.VPC != nil {
+			result = append(result, li.VPC.SubnetID)
+		}
+	}
+
+	return result
+}
+
+// vlanIPAMAddress returns the first VLAN IPAM address configured in the
+// interface and linode_interface blocks, without its prefix length.
+func (c *Config) vlanIPAMAddress() string {
+	for _, i := range c.legacyInterfaces() {
+		if i.Purpose == "vlan" && i.IPAMAddress != "" {
+			address, _, _ := strings.Cut(i.IPAMAddress, "/")
+			return address
+		}
+	}
+
+	for _, li := range c.LinodeInterfaces {
+		if li.VLAN != nil && li.VLAN.IPAMAddress != nil && *li.VLAN.IPAMAddress != "" {
+			address, _, _ := strings.Cut(*li.VLAN.IPAMAddress, "/")
+			return address
+		}
+	}
+
+	return ""
+}
+
+// publicIPv4Address returns the first public IPv4 address of the instance,
+// falling back to the 1:1 NAT address of a VPC interface.
+func publicIPv4Address(ips *linodego.InstanceIPAddressResponse) string {
+	if ips == nil || ips.IPv4 == nil {
+		return ""
+	}
+
+	for _, ip := range ips.IPv4.Public {
+		if ip != nil && ip.Address != "" {
+			return ip.Address
+		}
+	}
+
+	for _, ip := range ips.IPv4.VPC {
+		if ip != nil && ip.NAT1To1 != nil && *ip.NAT1To1 != "" {
+			return *ip.NAT1To1
+		}
+	}
+
+	return ""
+}
+
+// publicIPv6Address returns the SLAAC IPv6 address of the instance.
+func publicIPv6Address(ips *linodego.InstanceIPAddressResponse) string {
+	if ips == nil || ips.IPv6 == nil || ips.IPv6.SLAAC == nil {
+		return ""
+	}
+	return ips.IPv6.SLAAC.Address
+}
+
+// privateIPv4Address returns the first private IPv4 address of the instance.
+func privateIPv4Address(ips *linodego.InstanceIPAddressResponse) string {
+	if ips == nil || ips.IPv4 == nil {
+		return ""
+	}
+
+	for _, ip := range ips.IPv4.Private {
+		if ip != nil && ip.Address != "" {
+			return ip.Address
+		}
+	}
+	return ""
+}
+
+// vpcIPv4Address returns the VPC IPv4 address of the instance. When subnet IDs
+// are given, addresses in those subnets are preferred.
+func vpcIPv4Address(ips *linodego.InstanceIPAddressResponse, subnetIDs []int) string {
+	if ips == nil || ips.IPv4 == nil {
+		return ""
+	}
+
+	var fallback string
+	for _, ip := range ips.IPv4.VPC {
+		if ip == nil || ip.Address == nil || *ip.Address == "" {
+			continue
+		}
+		if len(subnetIDs) == 0 || slices.Contains(subnetIDs, ip.SubnetID) {
+			return *ip.Address
+		}
+		if fallback == "" {
+			fallback = *ip.Address
+		}
+	}
+	return fallback
+}
+
+// resolveSSHAddress works out the address to connect to based on the
+// ssh_interface option, the instance's IP assignments and the interface
+// configuration. When ssh_interface is not set, the public IPv4 address
+// is used, falling back to the public IPv6 address for IPv6-only instances.
+func resolveSSHAddress(c *Config, instanceID int, ips *linodego.InstanceIPAddressResponse) (string, error) {
+	var address string
+
+	switch c.SSHInterface {
+	case sshInterfacePublicIPv4:
+		address = publicIPv4Address(ips)
+	case sshInterfacePublicIPv6:
+		address = publicIPv6Address(ips)
+	case sshInterfacePrivateIPv4:
+		address = privateIPv4Address(ips)
+	case sshInterfaceVPCIPv4:
+		address = vpcIPv4Address(ips, c.vpcSubnetIDs())
+	case sshInterfaceVLAN:
+		address = c.vlanIPAMAddress()
+	case "":
+		address = publicIPv4Address(ips)
+		if address == "" {
+			address = publicIPv6Address(ips)
+		}
+		if address == "" {
+			return "", fmt.Errorf("linode instance %d has no public IPv4 or IPv6 addresses", instanceID)
+		}
+		return address, nil
+	default:
+		return "", fmt.Errorf("unknown ssh_interface %q", c.SSHInterface)
+	}
+
+	if address == "" {
+		return "", fmt.Errorf("linode instance %d has no address for ssh_interface %q", instanceID, c.SSHInterface)
+	}
+	return address, nil
+}
@@ -0,0 +1,90 @@
+package linode
+
+import (
+	"testing"
+
+	"github.com/linode/linodego"
+)
+
+func TestResolveSSHAddress(t *testing.T) {
+	vpcAddress := "10.0.0.5"
+	otherVPCAddress := "10.1.0.5"
+	natAddress := "172.104.1.3"
+	vlanIPAM := "10.10.0.2/24"
+
+	ips := &linodego.InstanceIPAddressResponse{
+		IPv4: &linodego.InstanceIPv4Response{
+			Public:  []*linodego.InstanceIP{{Address: "172.104.1.2"}},
+			Private: []*linodego.InstanceIP{{Address: "192.168.128.5"}},
+			VPC: []*linodego.VPCIP{
+				{Address: &otherVPCAddress, SubnetID: 2},
+				{Address: &vpcAddress, SubnetID: 1},
+			},
+		},
+		IPv6: &linodego.InstanceIPv6Response{
+			SLAAC: &linodego.InstanceIP{Address: "2600:3c03::f03c:91ff:fe24:3a2f"},
+		},
+	}
+
+	subnetID := 1
+	c := &Config{
+		Interfaces: []Interface{
+			{Purpose: "public"},
+			{Purpose: "vpc", VPCInterfaceAttributes: VPCInterfaceAttributes{SubnetID: &subnetID}},
+		},
+		LinodeInterfaces: []LinodeInterface{
+			{VLAN: &VLANInterface{VLANLabel: "vlan-1", IPAMAddress: &vlanIPAM}},
+		},
+	}
+
+	tests := []struct {
+		sshInterface string
+		ips          *linodego.InstanceIPAddressResponse
+		expected     string
+		expectErr    bool
+	}{
+		{sshInterface: "", ips: ips, expected: "172.104.1.2"},
+		{sshInterface: sshInterfacePublicIPv4, ips: ips, expected: "172.104.1.2"},
+		{sshInterface: sshInterfacePublicIPv6, ips: ips, expected: "2600:3c03::f03c:91ff:fe24:3a2f"},
+		{sshInterface: sshInterfacePrivateIPv4, ips: ips, expected: "192.168.128.5"},
+		{sshInterface: sshInterfaceVPCIPv4, ips: ips, expected: vpcAddress},
+		{sshInterface: sshInterfaceVLAN, ips: ips, expected: "10.10.0.2"},
+		{
+			// IPv6-only instance falls back to the SLAAC address
+			sshInterface: "",
+			ips:          &linodego.InstanceIPAddressResponse{IPv6: ips.IPv6},
+			expected:     "2600:3c03::f03c:91ff:fe24:3a2f",
+		},
+		{
+			// VPC interface with 1:1 NAT and no public interface
+			sshInterface: sshInterfacePublicIPv4,
+			ips: &linodego.InstanceIPAddressResponse{
+				IPv4: &linodego.InstanceIPv4Response{
+					VPC: []*linodego.VPCIP{{Address: &vpcAddress, NAT1To1: &natAddress}},
+				},
+			},
+			expected: natAddress,
+		},
+		{sshInterface: sshInterfacePublicIPv4, ips: &linodego.InstanceIPAddressResponse{}, expectErr: true},
+		{sshInterface: "", ips: &linodego.InstanceIPAddressResponse{}, expectErr: true},
+		{sshInterface: "bogus", ips: ips, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		c.SSHInterface = tt.sshInterface
+		got, err := resolveSSHAddress(c, 123, tt.ips)
+		if tt.expectErr {
+			if err == nil {
+				t.Errorf("ssh_interface %q: expected error, got %q", tt.sshInterface, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ssh_interface %q: unexpected error: %s", tt.sshInterface, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ssh_interface %q: got %q, expected %q", tt.sshInterface, got, tt.expected)
+		}
+	}
+}
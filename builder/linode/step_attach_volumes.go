@@ -0,0 +1,154 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+	"github.com/linode/packer-plugin-linode/helper"
+)
+
+// attachedVolume is a Block Storage volume attached to the build Linode.
+type attachedVolume struct {
+	id              int
+	label           string
+	deleteOnCleanup bool
+}
+
+// stepAttachVolumes creates the Block Storage volumes of the volume blocks and
+// attaches them, along with the existing volumes, to the build Linode.
+// The volumes are detached, and deleted if requested, during cleanup.
+type stepAttachVolumes struct {
+	client  *linodego.Client
+	volumes []attachedVolume
+}
+
+func (s *stepAttachVolumes) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("config").(*Config)
+	ui := state.Get("ui").(packersdk.Ui)
+	instance := state.Get("instance").(*linodego.Instance)
+
+	handleError := func(prefix string, err error) multistep.StepAction {
+		return helper.ErrorHelper(state, ui, prefix, err)
+	}
+
+	// Attach the volumes to the booted configuration profile of custom disk
+	// builds, otherwise to the only configuration profile of the Linode.
+	var configID int
+	if v, ok := state.GetOk("boot_config_id"); ok {
+		configID = v.(int)
+	}
+
+	for _, v := range c.Volumes {
+		volumeID := v.VolumeID
+
+		if volumeID == 0 {
+			ui.Say(fmt.Sprintf("Creating volume %s...", v.Label))
+			// Only the volumes deleted during cleanup are temporary
+			tags := c.Tags
+			if *v.DeleteOnCleanup {
+				tags = withBuildMarker(tags)
+			}
+
+			volume, err := s.client.CreateVolume(ctx, linodego.VolumeCreateOptions{
+				Label:  v.Label,
+				Region: instance.Region,
+				Size:   v.Size,
+				Tags:   tags,
+			})
+			if err != nil {
+				return handleError(fmt.Sprintf("Failed to create volume %q", v.Label), err)
+			}
+			volumeID = volume.ID
+
+			// Track the volume right away so it is cleaned up if anything below fails
+			s.volumes = append(s.volumes, attachedVolume{
+				id:              volume.ID,
+				label:           volume.Label,
+				deleteOnCleanup: *v.DeleteOnCleanup,
+			})
+
+			if _, err := s.client.WaitForVolumeStatus(
+				ctx, volume.ID, linodego.VolumeActive, int(c.StateTimeout.Seconds())); err != nil {
+				return handleError(fmt.Sprintf("Failed to wait for volume %q", v.Label), err)
+			}
+		}
+
+		ui.Say(fmt.Sprintf("Attaching volume %d to Linode...", volumeID))
+		if _, err := s.client.AttachVolume(ctx, volumeID, &linodego.VolumeAttachOptions{
+			LinodeID: instance.ID,
+			ConfigID: configID,
+		}); err != nil {
+			return handleError(fmt.Sprintf("Failed to attach volume %d", volumeID), err)
+		}
+
+		if v.VolumeID != 0 {
+			s.volumes = append(s.volumes, attachedVolume{
+				id:              volumeID,
+				label:           strconv.Itoa(volumeID),
+				deleteOnCleanup: *v.DeleteOnCleanup,
+			})
+		}
+
+		if _, err := s.client.WaitForVolumeLinodeID(
+			ctx, volumeID, &instance.ID, int(c.StateTimeout.Seconds())); err != nil {
+			return handleError(fmt.Sprintf("Failed to wait for volume %d to be attached", volumeID), err)
+		}
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepAttachVolumes) Cleanup(state multistep.StateBag) {
+	if len(s.volumes) == 0 {
+		return
+	}
+
+	c := state.Get("config").(*Config)
+	ui := state.Get("ui").(packersdk.Ui)
+	instance := state.Get("instance").(*linodego.Instance)
+
+	ctx := context.Background()
+
+	for _, v := range s.volumes {
+		volume, err := s.client.GetVolume(ctx, v.id)
+		if err != nil {
+			ui.Error(fmt.Sprintf("Error getting volume %s: %s", v.label, err))
+			if v.deleteOnCleanup {
+				recordLeak(state, "volume", v.id, v.label, err)
+			}
+			continue
+		}
+
+		// Never detach a volume that has been attached to another Linode since
+		if volume.LinodeID != nil && *volume.LinodeID == instance.ID {
+			ui.Say(fmt.Sprintf("Detaching volume %s...", v.label))
+			if err := s.client.DetachVolume(ctx, v.id); err != nil {
+				ui.Error(fmt.Sprintf("Error detaching volume %s: %s", v.label, err))
+				if v.deleteOnCleanup {
+					recordLeak(state, "volume", v.id, v.label, err)
+				}
+				continue
+			}
+
+			if _, err := s.client.WaitForVolumeLinodeID(ctx, v.id, nil, int(c.StateTimeout.Seconds())); err != nil {
+				ui.Error(fmt.Sprintf("Error waiting for volume %s to be detached: %s", v.label, err))
+				if v.deleteOnCleanup {
+					recordLeak(state, "volume", v.id, v.label, err)
+				}
+				continue
+			}
+		}
+
+		if v.deleteOnCleanup {
+			ui.Say(fmt.Sprintf("Deleting volume %s...", v.label))
+			if err := s.client.DeleteVolume(ctx, v.id); err != nil {
+				ui.Error(fmt.Sprintf("Error deleting volume %s: %s", v.label, err))
+				recordLeak(state, "volume", v.id, v.label, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package linode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+	"github.com/linode/packer-plugin-linode/helper"
+)
+
+// stepBootRescue boots the Linode into Rescue Mode with the devices of the
+// boot configuration profile attached, then enables SSH in the rescue
+// environment through the Lish console.
+type stepBootRescue struct {
+	client *linodego.Client
+}
+
+func (s *stepBootRescue) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("config").(*Config)
+	ui := state.Get("ui").(packersdk.Ui)
+	instance := state.Get("instance").(*linodego.Instance)
+	diskLabelToID := state.Get("disk_label_to_id").(map[string]int)
+
+	handleError := func(prefix string, err error) multistep.StepAction {
+		return helper.ErrorHelper(state, ui, prefix, err)
+	}
+
+	devices, err := flattenInstanceConfigDevices(c.getBootConfig().Devices, diskLabelToID)
+	if err != nil {
+		return handleError("Failed to resolve rescue devices", err)
+	}
+
+	ui.Say("Booting Linode into rescue mode...")
+	if err := s.client.RescueInstance(ctx, instance.ID, linodego.InstanceRescueOptions{
+		Devices: devices,
+	}); err != nil {
+		return handleError("Failed to boot Linode into rescue mode", err)
+	}
+
+	instance, err = s.client.WaitForInstanceStatus(ctx, instance.ID, linodego.InstanceRunning, int(c.StateTimeout.Seconds()))
+	if err != nil {
+		return handleError("Failed to wait for Linode to be running", err)
+	}
+	state.Put("instance", instance)
+
+	username := c.Rescue.LishUsername
+	if username == "" {
+		profile, err := s.client.GetProfile(ctx)
+		if err != nil {
+			return handleError("Failed to get profile for Lish", err)
+		}
+		username = profile.Username
+	}
+
+	publicKey, err := rescuePublicKey(c.Comm)
+	if err != nil {
+		return handleError("Failed to prepare rescue SSH access", err)
+	}
+	if publicKey == "" {
+		return handleError("Failed to prepare rescue SSH access", errors.New("no SSH key to authorize"))
+	}
+
+	// The Lish host of automatically selected regions is only known now
+	rescue := *c.Rescue
+	if rescue.LishHost == "" {
+		rescue.LishHost = fmt.Sprintf("lish-%s.linode.com", instance.Region)
+	}
+
+	ui.Say(fmt.Sprintf("Enabling SSH in the rescue environment through %s...", rescue.LishHost))
+	console, err := dialLish(&rescue, username, instance.Label)
+	if err != nil {
+		return handleError("Failed to open Lish console", err)
+	}
+	defer console.Close()
+
+	setupCtx, cancel := context.WithTimeout(ctx, c.StateTimeout)
+	defer cancel()
+
+	err = console.runUntil(setupCtx, rescueSetupCommand(publicKey), rescueReadyMarker, 15*time.Second)
+	if err != nil {
+		return handleError("Failed to enable SSH in the rescue environment", err)
+	}
+
+	ui.Say("Rescue environment is ready")
+	return multistep.ActionContinue
+}
+
+func (s *stepBootRescue) Cleanup(state multistep.StateBag) {}
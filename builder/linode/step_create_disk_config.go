@@ -8,6 +8,7 @@ import (
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/packerbuilderdata"
 	"github.com/linode/linodego"
 	"github.com/linode/packer-plugin-linode/helper"
 )
@@ -15,7 +16,8 @@ import (
 // stepCreateDiskConfig creates custom disks and configuration profiles for a Linode instance.
 // This step runs after the instance is created without an image (when custom disks/configs are specified).
 type stepCreateDiskConfig struct {
-	client *linodego.Client
+	client        *linodego.Client
+	generatedData *packerbuilderdata.GeneratedData
 }
 
 func flattenDisk(d Disk) linodego.InstanceDiskCreateOptions {
@@ -303,6 +305,13 @@ func (s *stepCreateDiskConfig) Run(ctx context.Context, state multistep.StateBag
 			return handleError(fmt.Sprintf("Failed to wait for disk %q", diskCfg.Label), err)
 		}
 
+		// Disks inherit the encryption of the Linode, which must not silently fall back to unencrypted storage
+		if linodego.InstanceDiskEncryption(c.DiskEncryption) == linodego.InstanceDiskEncryptionEnabled &&
+			disk.DiskEncryption != linodego.InstanceDiskEncryptionEnabled {
+			return handleError(fmt.Sprintf("Failed to create disk %q", diskCfg.Label),
+				fmt.Errorf("disk encryption is %q instead of enabled", disk.DiskEncryption))
+		}
+
 		// Resolve a disk inconsistency where the disk may not be immediately bootable after creation
 		time.Sleep(1 * time.Second)
 
@@ -370,6 +379,25 @@ func (s *stepCreateDiskConfig) Run(ctx context.Context, state multistep.StateBag
 
 	state.Put("disk", imageDisk)
 
+	var sourceImage string
+	for _, d := range c.Disks {
+		if d.Label == bootDiskLabel {
+			sourceImage = d.Image
+			break
+		}
+	}
+
+	s.generatedData.Put("DiskID", imageDisk.ID)
+	s.generatedData.Put("SourceImage", sourceImage)
+	s.generatedData.Put("SourceImageUpdated", sourceImageUpdated(ctx, s.client, sourceImage))
+
+	state.Put("boot_config_id", bootConfigID)
+
+	// Rescue mode builds boot into Rescue Mode instead, see stepBootRescue
+	if c.Rescue != nil {
+		return multistep.ActionContinue
+	}
+
 	// Boot the instance with the selected configuration profile
 	if bootConfigID != 0 {
 		ui.Say(fmt.Sprintf("Booting Linode with config ID %d...", bootConfigID))
@@ -0,0 +1,246 @@
+package linode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+	"github.com/linode/packer-plugin-linode/helper"
+)
+
+// The services returning the public IPv4 and IPv6 addresses of the host
+// running Packer.
+var (
+	egressIPv4URL = "https://api.ipify.org"
+	egressIPv6URL = "https://api6.ipify.org"
+)
+
+// firewallLabelRe matches the characters not allowed in firewall labels.
+var firewallLabelRe = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// firewallLabelMaxLength is the maximum length of firewall labels.
+const firewallLabelMaxLength = 32
+
+// stepCreateFirewall creates a temporary Cloud Firewall only allowing SSH
+// from the configured CIDRs, for stepCreateLinode to attach to the Linode.
+type stepCreateFirewall struct {
+	client     *linodego.Client
+	firewallID int
+}
+
+func (c *Config) validateTemporaryFirewall() []error {
+	var errs []error
+
+	if !c.TemporaryFirewall {
+		if len(c.TemporaryFirewallSourceCIDRs) > 0 {
+			errs = append(errs, errors.New("temporary_firewall_source_cidrs requires temporary_firewall"))
+		}
+		return errs
+	}
+
+	if c.FirewallID != 0 {
+		errs = append(errs, errors.New("firewall_id and temporary_firewall cannot be specified together"))
+	}
+
+	for i, li := range c.LinodeInterfaces {
+		if li.FirewallID != nil {
+			errs = append(errs, fmt.Errorf(
+				"linode_interface[%d]: firewall_id cannot be specified with temporary_firewall", i))
+		}
+	}
+
+	// The public IP addresses of this host are only allowed by default, which
+	// locks out SSH connections coming from anywhere else
+	if len(c.TemporaryFirewallSourceCIDRs) == 0 {
+		switch {
+		case c.Comm.SSHBastionHost != "":
+			errs = append(errs, errors.New(
+				"temporary_firewall_source_cidrs is required with ssh_bastion_host, as SSH does not come from this host"))
+		case c.Comm.SSHProxyHost != "":
+			errs = append(errs, errors.New(
+				"temporary_firewall_source_cidrs is required with ssh_proxy_host, as SSH does not come from this host"))
+		case !slices.Contains([]string{"", sshInterfacePublicIPv4, sshInterfacePublicIPv6}, c.SSHInterface):
+			errs = append(errs, fmt.Errorf(
+				"temporary_firewall_source_cidrs is required when ssh_interface is %s, as SSH does not "+
+					"come from the public IP address of this host", c.SSHInterface))
+		}
+	}
+
+	for _, cidr := range c.TemporaryFirewallSourceCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, fmt.Errorf("invalid temporary_firewall_source_cidrs entry %q: %w", cidr, err))
+		}
+	}
+
+	return errs
+}
+
+// firewallLabel returns the label of the temporary firewall of an instance.
+func firewallLabel(instanceLabel string) string {
+	label := firewallLabelRe.ReplaceAllString(instanceLabel, "-")
+	if len(label) > firewallLabelMaxLength {
+		label = label[:firewallLabelMaxLength]
+	}
+	return label
+}
+
+// detectEgressIP returns the CIDR of the public IP address of the host, as
+// returned by the given service.
+func detectEgressIP(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64))
+	if err != nil {
+		return "", err
+	}
+
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil {
+		return "", fmt.Errorf("%s returned an invalid IP address %q", url, body)
+	}
+	if ip.To4() != nil {
+		return ip.String() + "/32", nil
+	}
+	return ip.String() + "/128", nil
+}
+
+// detectEgressIPs returns the CIDRs of the public IPv4 and IPv6 addresses of
+// the host. Hosts may lack either address family, unless SSH connects over
+// it.
+func detectEgressIPs(ctx context.Context, sshInterface string) ([]string, error) {
+	var cidrs []string
+
+	ipv4, errIPv4 := detectEgressIP(ctx, egressIPv4URL)
+	if errIPv4 == nil {
+		cidrs = append(cidrs, ipv4)
+	}
+	ipv6, errIPv6 := detectEgressIP(ctx, egressIPv6URL)
+	if errIPv6 == nil {
+		cidrs = append(cidrs, ipv6)
+	}
+
+	switch {
+	case sshInterface == sshInterfacePublicIPv4 && errIPv4 != nil:
+		return nil, fmt.Errorf("failed to detect the public IPv4 address: %w", errIPv4)
+	case sshInterface == sshInterfacePublicIPv6 && errIPv6 != nil:
+		return nil, fmt.Errorf("failed to detect the public IPv6 address: %w", errIPv6)
+	case len(cidrs) == 0:
+		return nil, errors.Join(errIPv4, errIPv6)
+	}
+
+	if errIPv4 != nil {
+		log.Printf("[WARN] Failed to detect the public IPv4 address: %s", errIPv4)
+	}
+	if errIPv6 != nil {
+		log.Printf("[WARN] Failed to detect the public IPv6 address: %s", errIPv6)
+	}
+	return cidrs, nil
+}
+
+// firewallRules returns the rules of the temporary firewall, allowing SSH on
+// the given port from the given CIDRs.
+func firewallRules(cidrs []string, port int) linodego.FirewallRuleSet {
+	var ipv4, ipv6 []string
+	for _, cidr := range cidrs {
+		if ip, _, _ := net.ParseCIDR(cidr); ip.To4() != nil {
+			ipv4 = append(ipv4, cidr)
+		} else {
+			ipv6 = append(ipv6, cidr)
+		}
+	}
+
+	addresses := linodego.NetworkAddresses{}
+	if len(ipv4) > 0 {
+		addresses.IPv4 = &ipv4
+	}
+	if len(ipv6) > 0 {
+		addresses.IPv6 = &ipv6
+	}
+
+	return linodego.FirewallRuleSet{
+		Inbound: []linodego.FirewallRule{
+			{
+				Action:    "ACCEPT",
+				Label:     "packer-ssh",
+				Ports:     strconv.Itoa(port),
+				Protocol:  linodego.TCP,
+				Addresses: addresses,
+			},
+		},
+		InboundPolicy:  "DROP",
+		Outbound:       []linodego.FirewallRule{},
+		OutboundPolicy: "ACCEPT",
+	}
+}
+
+func (s *stepCreateFirewall) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("config").(*Config)
+	ui := state.Get("ui").(packersdk.Ui)
+
+	handleError := func(prefix string, err error) multistep.StepAction {
+		return helper.ErrorHelper(state, ui, prefix, err)
+	}
+
+	cidrs := c.TemporaryFirewallSourceCIDRs
+	if len(cidrs) == 0 {
+		ui.Say("Detecting the public IP address of this host...")
+		var err error
+		cidrs, err = detectEgressIPs(ctx, c.SSHInterface)
+		if err != nil {
+			return handleError(
+				"Failed to detect the public IP address, set temporary_firewall_source_cidrs instead", err)
+		}
+	}
+
+	ui.Say(fmt.Sprintf("Creating temporary firewall allowing SSH from %s...", strings.Join(cidrs, ", ")))
+	firewall, err := s.client.CreateFirewall(ctx, linodego.FirewallCreateOptions{
+		Label: firewallLabel(c.Label),
+		Rules: firewallRules(cidrs, c.Comm.Port()),
+		Tags:  withBuildMarker(c.Tags),
+	})
+	if err != nil {
+		return handleError("Failed to create temporary firewall", err)
+	}
+
+	s.firewallID = firewall.ID
+	state.Put("temporary_firewall_id", firewall.ID)
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateFirewall) Cleanup(state multistep.StateBag) {
+	if s.firewallID == 0 {
+		return
+	}
+
+	ui := state.Get("ui").(packersdk.Ui)
+
+	ui.Say(fmt.Sprintf("Deleting temporary firewall %d...", s.firewallID))
+	if err := s.client.DeleteFirewall(context.Background(), s.firewallID); err != nil {
+		ui.Error("Error cleaning up temporary firewall: " + err.Error())
+		recordLeak(state, "firewall", s.firewallID, "", err)
+	}
+}
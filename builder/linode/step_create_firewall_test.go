@@ -0,0 +1,121 @@
+package linode
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestFirewallLabel(t *testing.T) {
+	tests := map[string]string{
+		"packer-1700000000": "packer-1700000000",
+		"my build@2024":     "my-build-2024",
+		"a-very-long-instance-label-for-the-build": "a-very-long-instance-label-for-t",
+	}
+
+	for label, expected := range tests {
+		if got := firewallLabel(label); got != expected {
+			t.Errorf("%q: got %q, expected %q", label, got, expected)
+		}
+	}
+}
+
+func TestFirewallRules(t *testing.T) {
+	rules := firewallRules([]string{"203.0.113.7/32", "2001:db8::/64"}, 2222)
+
+	if rules.InboundPolicy != "DROP" || rules.OutboundPolicy != "ACCEPT" {
+		t.Errorf("got policies %s/%s, expected DROP/ACCEPT", rules.InboundPolicy, rules.OutboundPolicy)
+	}
+	if len(rules.Inbound) != 1 {
+		t.Fatalf("got %d inbound rules, expected 1", len(rules.Inbound))
+	}
+
+	rule := rules.Inbound[0]
+	if rule.Action != "ACCEPT" || rule.Ports != "2222" || rule.Protocol != "TCP" {
+		t.Errorf("got rule %#v", rule)
+	}
+	if rule.Addresses.IPv4 == nil || !reflect.DeepEqual(*rule.Addresses.IPv4, []string{"203.0.113.7/32"}) {
+		t.Errorf("got IPv4 addresses %v", rule.Addresses.IPv4)
+	}
+	if rule.Addresses.IPv6 == nil || !reflect.DeepEqual(*rule.Addresses.IPv6, []string{"2001:db8::/64"}) {
+		t.Errorf("got IPv6 addresses %v", rule.Addresses.IPv6)
+	}
+}
+
+func TestDetectEgressIP(t *testing.T) {
+	response := "203.0.113.7\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	for body, expected := range map[string]string{
+		"203.0.113.7\n": "203.0.113.7/32",
+		"2001:db8::1":   "2001:db8::1/128",
+	} {
+		response = body
+		cidr, err := detectEgressIP(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if cidr != expected {
+			t.Errorf("got %q, expected %q", cidr, expected)
+		}
+	}
+
+	response = "<html>"
+	if _, err := detectEgressIP(context.Background(), server.URL); err == nil {
+		t.Error("expected an error for an invalid response")
+	}
+}
+
+func TestDetectEgressIPs(t *testing.T) {
+	ipv4 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("203.0.113.7"))
+	}))
+	defer ipv4.Close()
+	ipv6 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("2001:db8::1"))
+	}))
+	defer ipv6.Close()
+	unreachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unreachable.Close()
+
+	defer func(v4, v6 string) { egressIPv4URL, egressIPv6URL = v4, v6 }(egressIPv4URL, egressIPv6URL)
+
+	tests := []struct {
+		name         string
+		ipv4URL      string
+		ipv6URL      string
+		sshInterface string
+		expected     []string
+	}{
+		{"dual stack", ipv4.URL, ipv6.URL, "", []string{"203.0.113.7/32", "2001:db8::1/128"}},
+		{"IPv4 only", ipv4.URL, unreachable.URL, "", []string{"203.0.113.7/32"}},
+		{"IPv6 only", unreachable.URL, ipv6.URL, sshInterfacePublicIPv6, []string{"2001:db8::1/128"}},
+		{"no IPv6 for public_ipv6", ipv4.URL, unreachable.URL, sshInterfacePublicIPv6, nil},
+		{"no IPv4 for public_ipv4", unreachable.URL, ipv6.URL, sshInterfacePublicIPv4, nil},
+		{"no address", unreachable.URL, unreachable.URL, "", nil},
+	}
+
+	for _, tt := range tests {
+		egressIPv4URL, egressIPv6URL = tt.ipv4URL, tt.ipv6URL
+
+		cidrs, err := detectEgressIPs(context.Background(), tt.sshInterface)
+		if tt.expected == nil {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %v", tt.name, cidrs)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.name, err)
+		} else if !reflect.DeepEqual(cidrs, tt.expected) {
+			t.Errorf("%s: got %v, expected %v", tt.name, cidrs, tt.expected)
+		}
+	}
+}
@@ -2,7 +2,13 @@ package linode
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
@@ -12,96 +18,312 @@ import (
 
 type stepCreateImage struct {
 	client *linodego.Client
+
+	// created maps the ID of every image created by the step to the image
+	// share groups it was added to, for Cleanup to remove them if the build
+	// fails. It is also available in state as created_images.
+	mu      sync.Mutex
+	created map[string][]int
+}
+
+// track records an image created by the step, and the image share groups it
+// is being added to.
+func (s *stepCreateImage) track(imageID string, shareGroupIDs ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.created[imageID] = append(s.created[imageID], shareGroupIDs...)
+}
+
+// imageJob describes an image to create from a disk of the instance.
+type imageJob struct {
+	diskID      int
+	diskLabel   string
+	label       string
+	description string
+}
+
+// imageJobs returns the images to create: the image of the disk in state,
+// followed by the images of the image_disks.
+func imageJobs(c *Config, disk *linodego.InstanceDisk, diskLabelToID map[string]int) ([]imageJob, error) {
+	jobs := []imageJob{
+		{
+			diskID:      disk.ID,
+			diskLabel:   disk.Label,
+			label:       c.ImageLabel,
+			description: c.Description,
+		},
+	}
+
+	for _, d := range c.ImageDisks {
+		diskID, err := resolveDiskLabel(d.DiskLabel, diskLabelToID)
+		if err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, imageJob{
+			diskID:      diskID,
+			diskLabel:   d.DiskLabel,
+			label:       d.ImageLabel,
+			description: d.Description,
+		})
+	}
+
+	return jobs, nil
+}
+
+// createImage starts the creation of the image of a disk.
+func (s *stepCreateImage) createImage(ctx context.Context, c *Config, job imageJob) (*linodego.Image, error) {
+	createOpts := linodego.ImageCreateOptions{
+		DiskID:      job.diskID,
+		Label:       job.label,
+		Description: job.description,
+		CloudInit:   c.CloudInit,
+	}
+	// Images are marked as temporary until the build succeeds
+	tags := withBuildMarker(c.ImageTags)
+	createOpts.Tags = &tags
+
+	image, err := s.client.CreateImage(ctx, createOpts)
+	if err != nil {
+		return nil, err
+	}
+	s.track(image.ID)
+	return image, nil
+}
+
+// waitForImagize waits for the disk job creating the image to finish, as a
+// Linode runs a single disk job at a time.
+func (s *stepCreateImage) waitForImagize(
+	ctx context.Context,
+	c *Config,
+	instanceID int,
+	image *linodego.Image,
+	started time.Time,
+) error {
+	if image.Created != nil {
+		started = *image.Created
+	}
+
+	_, err := s.client.WaitForEventFinished(
+		ctx, instanceID, linodego.EntityLinode, linodego.ActionDiskImagize, started, int(c.ImageCreateTimeout.Seconds()))
+	if err != nil {
+		return fmt.Errorf("failed to wait for the disk to be imaged: %w", err)
+	}
+	return nil
+}
+
+// finishImage waits for the created image to become available, then shares
+// and replicates it as configured.
+func (s *stepCreateImage) finishImage(
+	ctx context.Context,
+	c *Config,
+	ui packersdk.Ui,
+	image *linodego.Image,
+	buildRegion string,
+) (*linodego.Image, error) {
+	image, err := s.client.WaitForImageStatus(
+		ctx, image.ID, linodego.ImageStatusAvailable, int(c.ImageCreateTimeout.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for image creation: %w", err)
+	}
+
+	// Add the image to Image Share Groups, if configured. Groups are tracked
+	// before being joined, as a failed request may still have added the image.
+	for _, shareGroupID := range c.ImageShareGroupIDs {
+		s.track(image.ID, shareGroupID)
+		if err := helper.AddImageToShareGroups(ctx, s.client, ui, image.ID, []int{shareGroupID}); err != nil {
+			return nil, fmt.Errorf("failed to share the image: %w", err)
+		}
+	}
+
+	if len(c.ImageRegions) > 0 {
+		replicated, err := helper.ReplicateImage(
+			ctx, s.client, ui, image.ID, helper.ReplicationRegions(c.ImageRegions, buildRegion), c.ImageReplicationTimeout)
+		if err := helper.HandleReplicationFailure(ui, image.ID, err, c.ImageReplicationFailure); err != nil {
+			return nil, fmt.Errorf("failed to replicate the image: %w", err)
+		}
+		return replicated, nil
+	}
+
+	image, err = s.client.GetImage(ctx, image.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+	return image, nil
+}
+
+// deleteReplacedImages removes the images replaced by a new image from the
+// image share groups and deletes them, returning the IDs of the deleted
+// images. Failures are reported without failing the build, as the new image
+// is already available.
+func (s *stepCreateImage) deleteReplacedImages(
+	ctx context.Context,
+	c *Config,
+	ui packersdk.Ui,
+	images []linodego.Image,
+) []string {
+	var deleted []string
+	for _, image := range images {
+		if len(c.ImageShareGroupIDs) > 0 {
+			if err := helper.RemoveImageFromShareGroups(ctx, s.client, ui, image.ID, c.ImageShareGroupIDs); err != nil {
+				ui.Error(fmt.Sprintf("Failed to replace image %s: %s", image.ID, err))
+				continue
+			}
+		}
+
+		ui.Say(fmt.Sprintf("Deleting replaced image %s...", image.ID))
+		if err := s.client.DeleteImage(ctx, image.ID); err != nil {
+			ui.Error(fmt.Sprintf("Failed to delete replaced image %s: %s", image.ID, err))
+			continue
+		}
+		deleted = append(deleted, image.ID)
+	}
+	return deleted
 }
 
 func (s *stepCreateImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	c := state.Get("config").(*Config)
 	ui := state.Get("ui").(packersdk.Ui)
 	disk := state.Get("disk").(*linodego.InstanceDisk)
-	instance := state.Get("instance").(*linodego.Instance)
 
 	handleError := func(prefix string, err error) multistep.StepAction {
 		return helper.ErrorHelper(state, ui, prefix, err)
 	}
 
-	creationPoller, err := s.client.NewEventPoller(
-		ctx, instance.ID, linodego.EntityLinode, linodego.ActionDiskImagize)
-	if err != nil {
-		return handleError("Failed to create event poller", err)
+	var diskLabelToID map[string]int
+	if v, ok := state.GetOk("disk_label_to_id"); ok {
+		diskLabelToID = v.(map[string]int)
 	}
 
-	ui.Say("Creating image...")
-	image, err := s.client.CreateImage(ctx, linodego.ImageCreateOptions{
-		DiskID:      disk.ID,
-		Label:       c.ImageLabel,
-		Description: c.Description,
-		CloudInit:   c.CloudInit,
-	})
+	jobs, err := imageJobs(c, disk, diskLabelToID)
 	if err != nil {
-		return handleError("Failed to create image", err)
+		return handleError("Failed to resolve image disks", err)
 	}
 
-	_, err = creationPoller.WaitForFinished(ctx, int(c.ImageCreateTimeout.Seconds()))
-	if err != nil {
-		return handleError("Failed to wait for image creation", err)
-	}
-
-	// Add the image to Image Share Groups, if configured
-	if len(c.ImageShareGroupIDs) > 0 {
-		for _, shareGroupID := range c.ImageShareGroupIDs {
-			ui.Say(fmt.Sprintf(
-				"Adding image %s to image share group %d...",
-				image.ID,
-				shareGroupID,
-			))
-
-			_, err := s.client.ImageShareGroupAddImages(
-				ctx,
-				shareGroupID,
-				linodego.ImageShareGroupAddImagesOptions{
-					Images: []linodego.ImageShareGroupImage{
-						{
-							ID: image.ID,
-						},
-					},
-				},
-			)
-			if err != nil {
-				return handleError(
-					fmt.Sprintf(
-						"Failed to add image %s to image share group %d",
-						image.ID,
-						shareGroupID,
-					),
-					err,
-				)
-			}
-		}
+	s.created = make(map[string][]int)
+	state.Put("created_images", s.created)
+
+	if len(jobs) == 1 {
+		ui.Say("Creating image...")
+	} else {
+		ui.Say(fmt.Sprintf("Creating %d images...", len(jobs)))
 	}
 
-	if len(c.ImageRegions) > 0 {
-		image, err = s.client.ReplicateImage(ctx, image.ID, linodego.ImageReplicateOptions{
-			Regions: c.ImageRegions,
-		})
+	// A Linode runs a single disk job at a time, so the images are created
+	// one after another. Waiting for the images to become available, sharing
+	// and replicating them runs in parallel.
+	instance := state.Get("instance").(*linodego.Instance)
+	images := make([]*linodego.Image, len(jobs))
+	errs := make([]error, len(jobs))
+
+	var wg sync.WaitGroup
+	var createErr error
+	for i, job := range jobs {
+		if len(jobs) > 1 {
+			ui.Say(fmt.Sprintf("Creating image %s from disk %s...", job.label, job.diskLabel))
+		}
+
+		started := time.Now()
+		created, err := s.createImage(ctx, c, job)
 		if err != nil {
-			return handleError("Failed to replicate the image", err)
+			createErr = fmt.Errorf("image %q of disk %q: %w", job.label, job.diskLabel, err)
+			break
 		}
 
-		for _, r := range c.ImageRegions {
-			_, err = s.client.WaitForImageRegionStatus(ctx, image.ID, r, linodego.ImageRegionStatusAvailable)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			image, err := s.finishImage(ctx, c, ui, created, instance.Region)
 			if err != nil {
-				return handleError("Failed to wait for the image replication", err)
+				errs[i] = fmt.Errorf("image %q of disk %q: %w", job.label, job.diskLabel, err)
+				return
+			}
+			images[i] = image
+		}()
+
+		if i < len(jobs)-1 {
+			if err := s.waitForImagize(ctx, c, instance.ID, created, started); err != nil {
+				createErr = fmt.Errorf("image %q of disk %q: %w", job.label, job.diskLabel, err)
+				break
 			}
 		}
 	}
+	wg.Wait()
 
-	image, err = s.client.GetImage(ctx, image.ID)
-	if err != nil {
-		return handleError("Failed to get image", err)
+	errs = append(errs, createErr)
+	if err := errors.Join(errs...); err != nil {
+		return handleError("Failed to create image", err)
+	}
+
+	// The build succeeded, so its images are no longer temporary
+	for i, image := range images {
+		tags := slices.Clone(c.ImageTags)
+		if tags == nil {
+			tags = []string{}
+		}
+
+		image, err := s.client.UpdateImage(ctx, image.ID, linodego.ImageUpdateOptions{Tags: &tags})
+		if err != nil {
+			return handleError(fmt.Sprintf("Failed to tag image %s", images[i].ID), err)
+		}
+		images[i] = image
+	}
+
+	if v, ok := state.GetOk("existing_images"); ok {
+		existing := v.(map[string][]linodego.Image)
+		replaced := make(map[string][]string, len(images))
+		for i, job := range jobs {
+			replaced[images[i].ID] = s.deleteReplacedImages(ctx, c, ui, existing[job.label])
+		}
+		state.Put("replaced_image_ids", replaced)
 	}
 
-	state.Put("image", image)
+	state.Put("image", images[0])
+	state.Put("images", images)
 	return multistep.ActionContinue
 }
 
-func (s *stepCreateImage) Cleanup(state multistep.StateBag) {}
+// Cleanup removes the images created by the step from the image share groups
+// they joined and deletes them, unless the build succeeded or
+// keep_failed_image is set. With -on-error=abort, the runner skips Cleanup.
+func (s *stepCreateImage) Cleanup(state multistep.StateBag) {
+	_, cancelled := state.GetOk(multistep.StateCancelled)
+	_, halted := state.GetOk(multistep.StateHalted)
+	if !cancelled && !halted {
+		return
+	}
+
+	v, ok := state.GetOk("created_images")
+	if !ok {
+		return
+	}
+	created := v.(map[string][]int)
+	if len(created) == 0 {
+		return
+	}
+
+	ui := state.Get("ui").(packersdk.Ui)
+	c := state.Get("config").(*Config)
+
+	imageIDs := slices.Sorted(maps.Keys(created))
+	if c.KeepFailedImage {
+		ui.Say(fmt.Sprintf("Keeping failed images: %s", strings.Join(imageIDs, ", ")))
+		return
+	}
+
+	ctx := context.Background()
+	for _, imageID := range imageIDs {
+		if shareGroupIDs := created[imageID]; len(shareGroupIDs) > 0 {
+			if err := helper.RemoveImageFromShareGroups(ctx, s.client, ui, imageID, shareGroupIDs); err != nil {
+				ui.Error("Error removing image from image share groups: " + err.Error())
+			}
+		}
+
+		ui.Say(fmt.Sprintf("Deleting image %s...", imageID))
+		if err := s.client.DeleteImage(ctx, imageID); err != nil {
+			ui.Error("Error cleaning up image: " + err.Error())
+			recordLeak(state, "image", imageID, "", err)
+		}
+	}
+}
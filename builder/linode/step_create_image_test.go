@@ -0,0 +1,219 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+	"github.com/linode/packer-plugin-linode/helper"
+)
+
+// newTestClient returns a client of the test API server, polling quickly.
+func newTestClient(t *testing.T, server *httptest.Server) *linodego.Client {
+	c := helper.LinodeCommon{PersonalAccessToken: "secret", APIURL: server.URL}
+	client, err := c.NewClient()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	client.SetPollDelay(10 * time.Millisecond)
+	return client
+}
+
+func TestImageJobs(t *testing.T) {
+	c := &Config{
+		ImageLabel:  "packer-image",
+		Description: "boot disk",
+		ImageDisks: []ImageDisk{
+			{DiskLabel: "data", ImageLabel: "packer-image-data", Description: "data disk"},
+		},
+	}
+	disk := &linodego.InstanceDisk{ID: 1, Label: "boot"}
+
+	jobs, err := imageJobs(c, disk, map[string]int{"boot": 1, "data": 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []imageJob{
+		{diskID: 1, diskLabel: "boot", label: "packer-image", description: "boot disk"},
+		{diskID: 2, diskLabel: "data", label: "packer-image-data", description: "data disk"},
+	}
+	if !reflect.DeepEqual(jobs, expected) {
+		t.Errorf("got %#v, expected %#v", jobs, expected)
+	}
+
+	// Unknown disk label
+	if _, err := imageJobs(c, disk, map[string]int{"boot": 1}); err == nil {
+		t.Error("expected error, got nil")
+	}
+
+	// Only the disk in state is imaged without image_disks
+	jobs, err = imageJobs(&Config{ImageLabel: "packer-image"}, disk, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(jobs) != 1 || jobs[0].diskID != 1 {
+		t.Errorf("got %#v, expected a single job for disk 1", jobs)
+	}
+}
+
+func TestImageLabels(t *testing.T) {
+	c := &Config{
+		ImageLabel: "packer-image",
+		ImageDisks: []ImageDisk{{DiskLabel: "data", ImageLabel: "packer-image-data"}},
+	}
+
+	if labels := imageLabels(c); !reflect.DeepEqual(labels, []string{"packer-image", "packer-image-data"}) {
+		t.Errorf("got %v", labels)
+	}
+}
+
+func TestStepCreateImageCleanup(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *Config
+		outcome  string
+		expected []string
+	}{
+		{
+			name:    "halted",
+			config:  &Config{},
+			outcome: multistep.StateHalted,
+			expected: []string{
+				"/v4/images/sharegroups/10/images/shared/1",
+				"/v4/images/private/1",
+				"/v4/images/private/2",
+			},
+		},
+		{
+			name:    "cancelled",
+			config:  &Config{},
+			outcome: multistep.StateCancelled,
+			expected: []string{
+				"/v4/images/sharegroups/10/images/shared/1",
+				"/v4/images/private/1",
+				"/v4/images/private/2",
+			},
+		},
+		{
+			name:    "keep_failed_image",
+			config:  &Config{KeepFailedImage: true},
+			outcome: multistep.StateHalted,
+		},
+		{
+			name:   "succeeded",
+			config: &Config{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu      sync.Mutex
+				deleted []string
+			)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+
+				if r.Method == http.MethodDelete {
+					mu.Lock()
+					deleted = append(deleted, r.URL.Path)
+					mu.Unlock()
+					_, _ = w.Write([]byte(`{}`))
+					return
+				}
+				if r.URL.Path != "/v4/images/sharegroups/10/images" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				_, _ = w.Write([]byte(`{"page": 1, "pages": 1, "results": 1, "data": [
+					{"id": "shared/1", "image_sharing": {"shared_by": {"source_image_id": "private/1"}}}
+				]}`))
+			}))
+			defer server.Close()
+
+			state := new(multistep.BasicStateBag)
+			state.Put("config", tt.config)
+			state.Put("ui", packersdk.TestUi(t))
+			state.Put("leaked_resources", &leakedResources{})
+			state.Put("created_images", map[string][]int{"private/1": {10}, "private/2": nil})
+			if tt.outcome != "" {
+				state.Put(tt.outcome, true)
+			}
+
+			step := &stepCreateImage{client: newTestClient(t, server)}
+			step.Cleanup(state)
+
+			if !reflect.DeepEqual(deleted, tt.expected) {
+				t.Errorf("got deletions %v, expected %v", deleted, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStepCreateImageRun_OneDiskJobAtATime(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		busy    bool
+		created int
+	)
+
+	// The Linode rejects disk jobs while the previous image is being created
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v4/images":
+			if busy {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"errors": [{"reason": "Linode busy."}]}`))
+				return
+			}
+			busy = true
+			created++
+			_, _ = fmt.Fprintf(w, `{"id": "private/%d", "status": "creating", "created": "2024-01-01T00:00:00"}`, created)
+		case r.URL.Path == "/v4/account/events":
+			busy = false
+			_, _ = w.Write([]byte(`{"page": 1, "pages": 1, "data": [{"id": 1, "action": "disk_imagize",
+				"status": "finished", "entity": {"id": 100, "type": "linode"}, "created": "2024-01-01T00:00:00"}]}`))
+		case strings.HasPrefix(r.URL.Path, "/v4/images/private/"):
+			id := strings.TrimPrefix(r.URL.Path, "/v4/images/")
+			_, _ = fmt.Fprintf(w, `{"id": %q, "status": "available"}`, id)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	state := new(multistep.BasicStateBag)
+	state.Put("config", &Config{
+		ImageLabel:         "packer-image",
+		ImageDisks:         []ImageDisk{{DiskLabel: "data", ImageLabel: "packer-image-data"}},
+		ImageCreateTimeout: time.Minute,
+	})
+	state.Put("ui", packersdk.TestUi(t))
+	state.Put("instance", &linodego.Instance{ID: 100})
+	state.Put("disk", &linodego.InstanceDisk{ID: 1, Label: "boot"})
+	state.Put("disk_label_to_id", map[string]int{"boot": 1, "data": 2})
+
+	step := &stepCreateImage{client: newTestClient(t, server)}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("got action %s, expected continue: %v", action, state.Get("error"))
+	}
+
+	images := state.Get("images").([]*linodego.Image)
+	if len(images) != 2 || images[0].ID != "private/1" || images[1].ID != "private/2" {
+		t.Errorf("got images %#v, expected private/1 and private/2", images)
+	}
+}
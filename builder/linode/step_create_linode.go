@@ -3,15 +3,30 @@ package linode
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/packerbuilderdata"
 	"github.com/linode/linodego"
 	"github.com/linode/packer-plugin-linode/helper"
 )
 
 type stepCreateLinode struct {
-	client *linodego.Client
+	client        *linodego.Client
+	generatedData *packerbuilderdata.GeneratedData
+
+	// placementGroupID is the ID of the temporary placement group created
+	// for the build, if any.
+	placementGroupID int
+
+	// vpcID and subnetID are the IDs of the temporary VPC and subnet created
+	// for the build, if any.
+	vpcID    int
+	subnetID int
 }
 
 func flattenConfigInterfaceIPv4(i *InterfaceIPv4) *linodego.VPCIPv4 {
@@ -159,6 +174,57 @@ func flattenMetadata(m Metadata) *linodego.InstanceMetadataOptions {
 	}
 }
 
+// instanceIPAddresses returns the first public IPv4, the first private IPv4 and
+// the SLAAC IPv6 address (without prefix length) of the given instance.
+func instanceIPAddresses(instance *linodego.Instance) (publicIPv4, privateIPv4, ipv6 string) {
+	for _, ip := range instance.IPv4 {
+		if ip == nil {
+			continue
+		}
+		if ip.IsPrivate() {
+			if privateIPv4 == "" {
+				privateIPv4 = ip.String()
+			}
+		} else if publicIPv4 == "" {
+			publicIPv4 = ip.String()
+		}
+	}
+
+	ipv6, _, _ = strings.Cut(instance.IPv6, "/")
+	return
+}
+
+// sourceImageUpdated returns the last update time of the given image in RFC 3339
+// format, or an empty string if the image could not be retrieved.
+func sourceImageUpdated(ctx context.Context, client *linodego.Client, imageID string) string {
+	if imageID == "" {
+		return ""
+	}
+
+	image, err := client.GetImage(ctx, imageID)
+	if err != nil {
+		log.Printf("[WARN] Failed to get source image %s: %s", imageID, err)
+		return ""
+	}
+
+	if image.Updated == nil {
+		return ""
+	}
+	return image.Updated.Format(time.RFC3339)
+}
+
+// putInstanceData publishes the instance-related generated data.
+func (s *stepCreateLinode) putInstanceData(instance *linodego.Instance) {
+	publicIPv4, privateIPv4, ipv6 := instanceIPAddresses(instance)
+
+	s.generatedData.Put("InstanceID", instance.ID)
+	s.generatedData.Put("InstanceLabel", instance.Label)
+	s.generatedData.Put("InstanceIPv4", publicIPv4)
+	s.generatedData.Put("InstanceIPv6", ipv6)
+	s.generatedData.Put("PrivateIPv4", privateIPv4)
+	s.generatedData.Put("Region", instance.Region)
+}
+
 func (s *stepCreateLinode) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	c := state.Get("config").(*Config)
 	ui := state.Get("ui").(packersdk.Ui)
@@ -167,6 +233,15 @@ func (s *stepCreateLinode) Run(ctx context.Context, state multistep.StateBag) mu
 		return helper.ErrorHelper(state, ui, prefix, err)
 	}
 
+	if c.SourceLinodeID != 0 {
+		return s.cloneLinode(ctx, state)
+	}
+
+	regions, err := candidateRegions(ctx, s.client, c)
+	if err != nil {
+		return handleError("Failed to select a region", err)
+	}
+
 	ui.Say("Creating Linode...")
 
 	// Determine if we're using custom disks/configs (explicit provisioning)
@@ -176,13 +251,12 @@ func (s *stepCreateLinode) Run(ctx context.Context, state multistep.StateBag) mu
 
 	createOpts := linodego.InstanceCreateOptions{
 		PrivateIP:           c.PrivateIP,
-		Region:              c.Region,
-		Type:                c.InstanceType,
 		Label:               c.Label,
-		Tags:                c.Tags,
+		Tags:                withBuildMarker(c.Tags),
 		FirewallID:          c.FirewallID,
 		Metadata:            flattenMetadata(c.Metadata),
 		InterfaceGeneration: linodego.InterfaceGeneration(c.InterfaceGeneration),
+		DiskEncryption:      linodego.InstanceDiskEncryption(c.DiskEncryption),
 	}
 
 	// Only set image-related options when NOT using custom disks
@@ -221,6 +295,10 @@ func (s *stepCreateLinode) Run(ctx context.Context, state multistep.StateBag) mu
 		linodeInterfaces[i] = flattenLinodeInterface(v)
 	}
 
+	if c.TemporaryVPC != nil {
+		interfaces, linodeInterfaces = temporaryVPCInterfaces(c)
+	}
+
 	// Only add legacy interfaces to instance creation when NOT using custom disks
 	// (when using custom disks, legacy interfaces should be specified in the config block)
 	// linode_interface (newer system) can be specified at instance level regardless of disk mode
@@ -232,13 +310,45 @@ func (s *stepCreateLinode) Run(ctx context.Context, state multistep.StateBag) mu
 		createOpts.LinodeInterfaces = linodeInterfaces
 	}
 
+	// The temporary firewall protects the instance, or each of its interfaces
+	// that accepts a firewall
+	if v, ok := state.GetOk("temporary_firewall_id"); ok {
+		firewallID := v.(int)
+		if len(linodeInterfaces) == 0 {
+			createOpts.FirewallID = firewallID
+		}
+		for i := range linodeInterfaces {
+			if linodeInterfaces[i].Public != nil || linodeInterfaces[i].VPC != nil {
+				linodeInterfaces[i].FirewallID = linodego.Pointer(firewallID)
+			}
+		}
+	}
+
 	createOpts.AuthorizedKeys = append(createOpts.AuthorizedKeys, c.AuthorizedKeys...)
 	createOpts.AuthorizedUsers = append(createOpts.AuthorizedUsers, c.AuthorizedUsers...)
 
-	instance, err := s.client.CreateInstance(ctx, createOpts)
+	instance, instanceType, err := s.createInRegions(ctx, state, ui, c, regions, func(
+		region, instanceType string,
+		pg *linodego.InstanceCreatePlacementGroupOptions,
+	) (*linodego.Instance, error) {
+		createOpts.Region = region
+		createOpts.Type = instanceType
+		createOpts.PlacementGroup = pg
+
+		if c.TemporaryVPC != nil {
+			subnetID, err := s.temporarySubnet(ctx, c, region)
+			if err != nil {
+				return nil, err
+			}
+			setTemporarySubnet(&createOpts, subnetID)
+		}
+
+		return s.client.CreateInstance(ctx, createOpts)
+	})
 	if err != nil {
 		return handleError("Failed to create Linode Instance", err)
 	}
+	state.Put("linode_type", instanceType)
 	state.Put("instance", instance)
 	state.Put("instance_id", instance.ID)
 
@@ -251,12 +361,95 @@ func (s *stepCreateLinode) Run(ctx context.Context, state multistep.StateBag) mu
 			return handleError("Failed to wait for Linode to be offline", err)
 		}
 		state.Put("instance", instance)
-		// Disk will be set by stepCreateDiskConfig
+		s.putInstanceData(instance)
+		// Disk and source image will be set by stepCreateDiskConfig
 		return multistep.ActionContinue
 	}
 
+	return s.waitForInstanceReady(ctx, state, instance, c.Image)
+}
+
+// cloneLinode clones the source Linode into a temporary build instance and boots it.
+// The source Linode is never stored in the state bag, so it is never cleaned up.
+func (s *stepCreateLinode) cloneLinode(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("config").(*Config)
+	ui := state.Get("ui").(packersdk.Ui)
+
+	handleError := func(prefix string, err error) multistep.StepAction {
+		return helper.ErrorHelper(state, ui, prefix, err)
+	}
+
+	regions, err := candidateRegions(ctx, s.client, c)
+	if err != nil {
+		return handleError("Failed to select a region", err)
+	}
+
+	ui.Say(fmt.Sprintf("Cloning Linode %d...", c.SourceLinodeID))
+
+	instance, instanceType, err := s.createInRegions(ctx, state, ui, c, regions, func(
+		region, instanceType string,
+		pg *linodego.InstanceCreatePlacementGroupOptions,
+	) (*linodego.Instance, error) {
+		return s.client.CloneInstance(ctx, c.SourceLinodeID, linodego.InstanceCloneOptions{
+			Region:         region,
+			Type:           instanceType,
+			Label:          c.Label,
+			Disks:          c.SourceLinodeDiskIDs,
+			Configs:        c.SourceLinodeConfigIDs,
+			PrivateIP:      c.PrivateIP,
+			Metadata:       flattenMetadata(c.Metadata),
+			PlacementGroup: pg,
+		})
+	})
+	if err != nil {
+		return handleError("Failed to clone Linode Instance", err)
+	}
+	state.Put("linode_type", instanceType)
+	state.Put("instance", instance)
+	state.Put("instance_id", instance.ID)
+
+	// Cloned Linodes are left offline once their disks have been copied
+	instance, err = s.client.WaitForInstanceStatus(ctx, instance.ID, linodego.InstanceOffline, int(c.StateTimeout.Seconds()))
+	if err != nil {
+		return handleError("Failed to wait for the Linode clone to finish", err)
+	}
+	state.Put("instance", instance)
+
+	// Clones do not accept tags on creation
+	tags := withBuildMarker(c.Tags)
+	instance, err = s.client.UpdateInstance(ctx, instance.ID, linodego.InstanceUpdateOptions{
+		Tags: &tags,
+	})
+	if err != nil {
+		return handleError("Failed to tag the cloned Linode", err)
+	}
+	state.Put("instance", instance)
+
+	ui.Say(fmt.Sprintf("Booting cloned Linode %d...", instance.ID))
+	if err := s.client.BootInstance(ctx, instance.ID, 0); err != nil {
+		return handleError("Failed to boot the cloned Linode", err)
+	}
+
+	return s.waitForInstanceReady(ctx, state, instance, instance.Image)
+}
+
+// waitForInstanceReady waits for the instance to be running, then finds the disk
+// to be imaged and publishes the generated data.
+func (s *stepCreateLinode) waitForInstanceReady(
+	ctx context.Context,
+	state multistep.StateBag,
+	instance *linodego.Instance,
+	sourceImage string,
+) multistep.StepAction {
+	c := state.Get("config").(*Config)
+	ui := state.Get("ui").(packersdk.Ui)
+
+	handleError := func(prefix string, err error) multistep.StepAction {
+		return helper.ErrorHelper(state, ui, prefix, err)
+	}
+
 	// wait until instance is running
-	instance, err = s.client.WaitForInstanceStatus(ctx, instance.ID, linodego.InstanceRunning, int(c.StateTimeout.Seconds()))
+	instance, err := s.client.WaitForInstanceStatus(ctx, instance.ID, linodego.InstanceRunning, int(c.StateTimeout.Seconds()))
 	if err != nil {
 		return handleError("Failed to wait for Linode ready", err)
 	}
@@ -271,6 +464,11 @@ func (s *stepCreateLinode) Run(ctx context.Context, state multistep.StateBag) mu
 		return handleError("Failed to find instance disk", errors.New("no suitable disk was found"))
 	}
 	state.Put("disk", disk)
+
+	s.putInstanceData(instance)
+	s.generatedData.Put("DiskID", disk.ID)
+	s.generatedData.Put("SourceImage", sourceImage)
+	s.generatedData.Put("SourceImageUpdated", sourceImageUpdated(ctx, s.client, sourceImage))
 	return multistep.ActionContinue
 }
 
@@ -288,14 +486,71 @@ func (s *stepCreateLinode) findDisk(ctx context.Context, instanceID int) (*linod
 }
 
 func (s *stepCreateLinode) Cleanup(state multistep.StateBag) {
-	instance, ok := state.GetOk("instance")
-	if !ok {
-		return
+	ui := state.Get("ui").(packersdk.Ui)
+	c := state.Get("config").(*Config)
+
+	var instanceErr error
+	if instance, ok := state.GetOk("instance"); ok {
+		instanceID := instance.(*linodego.Instance).ID
+
+		// Never delete the Linode a build was cloned from
+		if c.SourceLinodeID == 0 || instanceID != c.SourceLinodeID {
+			if instanceErr = s.deleteInstance(ui, c, instanceID); instanceErr != nil {
+				ui.Error("Error cleaning up Linode: " + instanceErr.Error())
+				recordLeak(state, "instance", instanceID, instance.(*linodego.Instance).Label, instanceErr)
+			}
+		}
 	}
 
-	ui := state.Get("ui").(packersdk.Ui)
+	if s.placementGroupID != 0 {
+		if err := s.deletePlacementGroup(ui, c); err != nil {
+			ui.Error("Error cleaning up placement group: " + err.Error())
+			recordLeak(state, "placement_group", s.placementGroupID, "", err)
+		}
+	}
 
-	if err := s.client.DeleteInstance(context.Background(), instance.(*linodego.Instance).ID); err != nil {
-		ui.Error("Error cleaning up Linode: " + err.Error())
+	if s.vpcID != 0 {
+		// The VPC cannot be deleted while the Linode is still in its subnet
+		if instanceErr != nil {
+			recordLeak(state, "vpc", s.vpcID, c.TemporaryVPC.Label,
+				fmt.Errorf("the Linode in VPC %d could not be deleted: %w", s.vpcID, instanceErr))
+		} else if err := s.deleteTemporaryVPC(ui, c); err != nil {
+			ui.Error("Error cleaning up VPC: " + err.Error())
+			recordLeak(state, "vpc", s.vpcID, c.TemporaryVPC.Label, err)
+		}
 	}
 }
+
+// deleteInstance deletes the instance, retrying while it cannot be deleted,
+// and waits for it to be gone, for at most cleanup_timeout. An instance that
+// no longer exists is already deleted.
+func (s *stepCreateLinode) deleteInstance(ui packersdk.Ui, c *Config, instanceID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.CleanupTimeout)
+	defer cancel()
+
+	ui.Say(fmt.Sprintf("Deleting Linode %d...", instanceID))
+
+	err := helper.RetryWithBackoff(ctx, func() error {
+		err := s.client.DeleteInstance(ctx, instanceID)
+		if err != nil && !linodego.IsNotFound(err) {
+			log.Printf("[WARN] Failed to delete Linode %d, retrying: %s", instanceID, err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return helper.RetryWithBackoff(ctx, func() error {
+		_, err := s.client.GetInstance(ctx, instanceID)
+		switch {
+		case linodego.IsNotFound(err):
+			return nil
+		case err != nil:
+			return err
+		default:
+			return fmt.Errorf("Linode %d still exists", instanceID)
+		}
+	})
+}
@@ -1,8 +1,14 @@
 package linode
 
 import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
 	"github.com/linode/linodego"
 )
 
@@ -288,3 +294,79 @@ func TestFlattenMetadata(t *testing.T) {
 		}
 	})
 }
+
+func TestInstanceIPAddresses(t *testing.T) {
+	publicIP := net.ParseIP("172.104.1.2")
+	privateIP := net.ParseIP("192.168.128.5")
+
+	instance := &linodego.Instance{
+		IPv4: []*net.IP{&privateIP, &publicIP},
+		IPv6: "2600:3c03::f03c:91ff:fe24:3a2f/128",
+	}
+
+	gotPublic, gotPrivate, gotIPv6 := instanceIPAddresses(instance)
+	if gotPublic != "172.104.1.2" {
+		t.Errorf("public IPv4 = %q, want %q", gotPublic, "172.104.1.2")
+	}
+	if gotPrivate != "192.168.128.5" {
+		t.Errorf("private IPv4 = %q, want %q", gotPrivate, "192.168.128.5")
+	}
+	if gotIPv6 != "2600:3c03::f03c:91ff:fe24:3a2f" {
+		t.Errorf("IPv6 = %q, want %q", gotIPv6, "2600:3c03::f03c:91ff:fe24:3a2f")
+	}
+
+	gotPublic, gotPrivate, gotIPv6 = instanceIPAddresses(&linodego.Instance{})
+	if gotPublic != "" || gotPrivate != "" || gotIPv6 != "" {
+		t.Errorf("expected empty addresses, got %q %q %q", gotPublic, gotPrivate, gotIPv6)
+	}
+}
+
+func TestStepCreateLinodeDeleteInstance(t *testing.T) {
+	tests := []struct {
+		name        string
+		deleteCode  int
+		expectError bool
+	}{
+		{name: "deleted", deleteCode: http.StatusOK},
+		{name: "already deleted", deleteCode: http.StatusNotFound},
+		{name: "forbidden", deleteCode: http.StatusForbidden, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deletes atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+
+				code := http.StatusNotFound
+				if r.Method == http.MethodDelete {
+					deletes.Add(1)
+					code = tt.deleteCode
+				}
+				w.WriteHeader(code)
+				if code == http.StatusOK {
+					_, _ = w.Write([]byte(`{}`))
+				} else {
+					_, _ = w.Write([]byte(`{"errors": [{"reason": "` + http.StatusText(code) + `"}]}`))
+				}
+			}))
+			defer server.Close()
+
+			step := &stepCreateLinode{client: newTestClient(t, server)}
+
+			// Errors that retrying cannot fix must not wait for the timeout
+			c := &Config{CleanupTimeout: time.Minute}
+			started := time.Now()
+			err := step.deleteInstance(packersdk.TestUi(t), c, 1)
+			if tt.expectError != (err != nil) {
+				t.Fatalf("got error %v, expected error: %t", err, tt.expectError)
+			}
+			if elapsed := time.Since(started); elapsed > 10*time.Second {
+				t.Errorf("took %s, expected no retries", elapsed)
+			}
+			if n := deletes.Load(); n != 1 {
+				t.Errorf("got %d delete requests, expected 1", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+	"github.com/linode/packer-plugin-linode/helper"
+)
+
+// stepFindExistingImages looks up the private images labelled like the images
+// to create, to be replaced by them when image_force_replace is set.
+type stepFindExistingImages struct {
+	client *linodego.Client
+}
+
+// imageLabels returns the labels of the images to create.
+func imageLabels(c *Config) []string {
+	labels := []string{c.ImageLabel}
+	for _, d := range c.ImageDisks {
+		labels = append(labels, d.ImageLabel)
+	}
+	return labels
+}
+
+// findImagesByLabel returns the private images with the given label. Images
+// created automatically from deleted Linodes are left alone.
+func (s *stepFindExistingImages) findImagesByLabel(ctx context.Context, label string) ([]linodego.Image, error) {
+	filter := linodego.Filter{}
+	filter.AddField(linodego.Eq, "label", label)
+	filter.AddField(linodego.Eq, "is_public", false)
+	filterString, err := filter.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := s.client.ListImages(ctx, linodego.NewListOptions(0, string(filterString)))
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(images, func(image linodego.Image) bool {
+		return image.IsPublic || image.Type != "manual"
+	}), nil
+}
+
+func (s *stepFindExistingImages) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("config").(*Config)
+	ui := state.Get("ui").(packersdk.Ui)
+
+	handleError := func(prefix string, err error) multistep.StepAction {
+		return helper.ErrorHelper(state, ui, prefix, err)
+	}
+
+	ui.Say("Looking up existing images to replace...")
+
+	existing := make(map[string][]linodego.Image)
+	for _, label := range imageLabels(c) {
+		images, err := s.findImagesByLabel(ctx, label)
+		if err != nil {
+			return handleError(fmt.Sprintf("Failed to look up existing images labelled %q", label), err)
+		}
+
+		for _, image := range images {
+			ui.Say(fmt.Sprintf("Image %s (%s) will be replaced", image.ID, image.Label))
+		}
+		existing[label] = images
+	}
+
+	state.Put("existing_images", existing)
+	return multistep.ActionContinue
+}
+
+func (s *stepFindExistingImages) Cleanup(state multistep.StateBag) {}
@@ -0,0 +1,62 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+	"github.com/linode/packer-plugin-linode/helper"
+)
+
+// stepLeaveRescue unmounts the chroot and reboots the Linode from Rescue
+// Mode into the boot configuration profile.
+type stepLeaveRescue struct {
+	client *linodego.Client
+}
+
+func (s *stepLeaveRescue) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("config").(*Config)
+	ui := state.Get("ui").(packersdk.Ui)
+	instance := state.Get("instance").(*linodego.Instance)
+	bootConfigID := state.Get("boot_config_id").(int)
+
+	handleError := func(prefix string, err error) multistep.StepAction {
+		return helper.ErrorHelper(state, ui, prefix, err)
+	}
+
+	comm := state.Get("communicator").(packersdk.Communicator)
+	if chroot, ok := comm.(*chrootCommunicator); ok {
+		comm = chroot.comm
+		state.Put("communicator", comm)
+	}
+
+	ui.Say(fmt.Sprintf("Unmounting %s...", c.Rescue.MountPath))
+	if err := runRemoteCommand(ctx, ui, comm, "sync && umount -R "+shellQuote(c.Rescue.MountPath)); err != nil {
+		return handleError("Failed to unmount the root device", err)
+	}
+
+	ui.Say(fmt.Sprintf("Rebooting Linode with config ID %d...", bootConfigID))
+	rebootStarted := time.Now()
+	if err := s.client.RebootInstance(ctx, instance.ID, bootConfigID); err != nil {
+		return handleError("Failed to reboot Linode", err)
+	}
+
+	_, err := s.client.WaitForEventFinished(
+		ctx, instance.ID, linodego.EntityLinode, linodego.ActionLinodeReboot, rebootStarted, int(c.StateTimeout.Seconds()))
+	if err != nil {
+		return handleError("Failed to wait for Linode to reboot", err)
+	}
+
+	instance, err = s.client.GetInstance(ctx, instance.ID)
+	if err != nil {
+		return handleError("Failed to get Linode", err)
+	}
+	state.Put("instance", instance)
+
+	return multistep.ActionContinue
+}
+
+func (s *stepLeaveRescue) Cleanup(state multistep.StateBag) {}
@@ -0,0 +1,87 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/packer-plugin-linode/helper"
+)
+
+// stepMountChroot mounts the root device of the boot configuration profile in
+// the rescue environment and makes the provisioners run chrooted into it.
+type stepMountChroot struct{}
+
+// runRemoteCommand runs the command with the communicator and fails if it
+// exits with a non-zero status.
+func runRemoteCommand(ctx context.Context, ui packersdk.Ui, comm packersdk.Communicator, command string) error {
+	cmd := &packersdk.RemoteCmd{Command: command}
+	if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
+		return err
+	}
+
+	if status := cmd.ExitStatus(); status != 0 {
+		return fmt.Errorf("command %q exited with status %d", command, status)
+	}
+	return nil
+}
+
+// chrootMountsCommand returns the command that mounts the pseudo filesystems
+// needed by the chroot.
+func chrootMountsCommand(mountPath string) string {
+	root := shellQuote(mountPath)
+	return fmt.Sprintf(
+		"mkdir -p %[1]s/dev %[1]s/proc %[1]s/sys && "+
+			"mount --rbind /dev %[1]s/dev && "+
+			"mount -t proc proc %[1]s/proc && "+
+			"mount --rbind /sys %[1]s/sys",
+		root,
+	)
+}
+
+func (s *stepMountChroot) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("config").(*Config)
+	ui := state.Get("ui").(packersdk.Ui)
+	comm := state.Get("communicator").(packersdk.Communicator)
+
+	handleError := func(prefix string, err error) multistep.StepAction {
+		return helper.ErrorHelper(state, ui, prefix, err)
+	}
+
+	rootDevice := c.getBootConfig().RootDevice
+	mountPath := c.Rescue.MountPath
+
+	if len(c.Rescue.PreMountCommands) > 0 {
+		ui.Say("Running pre-mount commands...")
+	}
+	for _, command := range c.Rescue.PreMountCommands {
+		if err := runRemoteCommand(ctx, ui, comm, rescueEnvCommand(rootDevice, mountPath, command)); err != nil {
+			return handleError("Failed to run pre-mount command", err)
+		}
+	}
+
+	ui.Say(fmt.Sprintf("Mounting %s at %s...", rootDevice, mountPath))
+	mountCommand := fmt.Sprintf("mkdir -p %[2]s && mount %[1]s %[2]s", shellQuote(rootDevice), shellQuote(mountPath))
+	if err := runRemoteCommand(ctx, ui, comm, mountCommand); err != nil {
+		return handleError("Failed to mount the root device", err)
+	}
+
+	if len(c.Rescue.PostMountCommands) > 0 {
+		ui.Say("Running post-mount commands...")
+	}
+	for _, command := range c.Rescue.PostMountCommands {
+		if err := runRemoteCommand(ctx, ui, comm, rescueEnvCommand(rootDevice, mountPath, command)); err != nil {
+			return handleError("Failed to run post-mount command", err)
+		}
+	}
+
+	if err := runRemoteCommand(ctx, ui, comm, chrootMountsCommand(mountPath)); err != nil {
+		return handleError("Failed to mount the chroot filesystems", err)
+	}
+
+	state.Put("communicator", &chrootCommunicator{root: mountPath, comm: comm})
+	return multistep.ActionContinue
+}
+
+func (s *stepMountChroot) Cleanup(state multistep.StateBag) {}
@@ -0,0 +1,144 @@
+package linode
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+	"github.com/linode/packer-plugin-linode/helper"
+)
+
+const (
+	// diskUsageCommand prints the usage of the root filesystem in MB.
+	diskUsageCommand = "df -P -m /"
+
+	// zeroFreeSpaceCommand fills the free space of the root filesystem with
+	// zeros, then frees it again. dd fails once the disk is full.
+	zeroFreeSpaceCommand = "dd if=/dev/zero of=/packer-zero.fill bs=1M 2>/dev/null; rm -f /packer-zero.fill; sync"
+)
+
+// stepMeasureDiskUsage measures the used space of the root filesystem over
+// the communicator, so that stepShrinkDisk can shrink the disk once the
+// Linode is offline.
+type stepMeasureDiskUsage struct{}
+
+// parseDiskUsage returns the used space in MB from the POSIX output of df.
+func parseDiskUsage(output string) (int, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return 0, fmt.Errorf("unexpected df output: %q", output)
+	}
+
+	// Filesystem 1048576-blocks Used Available Capacity Mounted on
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 3 {
+		return 0, fmt.Errorf("unexpected df output: %q", output)
+	}
+
+	used, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return 0, fmt.Errorf("unexpected df output: %q", output)
+	}
+	return used, nil
+}
+
+func (s *stepMeasureDiskUsage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("config").(*Config)
+	ui := state.Get("ui").(packersdk.Ui)
+	comm := state.Get("communicator").(packersdk.Communicator)
+
+	handleError := func(prefix string, err error) multistep.StepAction {
+		return helper.ErrorHelper(state, ui, prefix, err)
+	}
+
+	if c.ImageShrinkZeroFree {
+		ui.Say("Zeroing the free space of the disk...")
+		if err := runRemoteCommand(ctx, ui, comm, zeroFreeSpaceCommand); err != nil {
+			return handleError("Failed to zero the free space of the disk", err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := &packersdk.RemoteCmd{
+		Command: diskUsageCommand,
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+	}
+	if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
+		return handleError("Failed to measure disk usage", err)
+	}
+	if status := cmd.ExitStatus(); status != 0 {
+		return handleError("Failed to measure disk usage", fmt.Errorf(
+			"command %q exited with status %d: %s", diskUsageCommand, status, strings.TrimSpace(stderr.String())))
+	}
+
+	used, err := parseDiskUsage(stdout.String())
+	if err != nil {
+		return handleError("Failed to measure disk usage", err)
+	}
+
+	ui.Say(fmt.Sprintf("The root filesystem uses %d MB", used))
+	state.Put("disk_used_mb", used)
+	return multistep.ActionContinue
+}
+
+func (s *stepMeasureDiskUsage) Cleanup(state multistep.StateBag) {}
+
+// stepShrinkDisk shrinks the disk to be imaged to its used space plus
+// image_shrink_margin. It must run while the Linode is offline.
+type stepShrinkDisk struct {
+	client *linodego.Client
+}
+
+func (s *stepShrinkDisk) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("config").(*Config)
+	ui := state.Get("ui").(packersdk.Ui)
+	instance := state.Get("instance").(*linodego.Instance)
+	disk := state.Get("disk").(*linodego.InstanceDisk)
+	used := state.Get("disk_used_mb").(int)
+
+	handleError := func(prefix string, err error) multistep.StepAction {
+		return helper.ErrorHelper(state, ui, prefix, err)
+	}
+
+	// Linode only resizes the ext3 and ext4 filesystems along with the disk
+	if disk.Filesystem != linodego.FilesystemExt3 && disk.Filesystem != linodego.FilesystemExt4 {
+		ui.Say(fmt.Sprintf("Skipping shrinking disk %s: %s filesystems cannot be resized", disk.Label, disk.Filesystem))
+		return multistep.ActionContinue
+	}
+
+	size := used + c.ImageShrinkMargin
+	if size >= disk.Size {
+		ui.Say(fmt.Sprintf("Skipping shrinking disk %s: it is already %d MB", disk.Label, disk.Size))
+		return multistep.ActionContinue
+	}
+
+	resizePoller, err := s.client.NewEventPoller(ctx, instance.ID, linodego.EntityLinode, linodego.ActionDiskResize)
+	if err != nil {
+		return handleError("Failed to create event poller", err)
+	}
+
+	ui.Say(fmt.Sprintf("Shrinking disk %s from %d MB to %d MB...", disk.Label, disk.Size, size))
+	if err := s.client.ResizeInstanceDisk(ctx, instance.ID, disk.ID, size); err != nil {
+		return handleError("Failed to shrink disk", err)
+	}
+
+	if _, err := resizePoller.WaitForFinished(ctx, int(c.StateTimeout.Seconds())); err != nil {
+		return handleError("Failed to wait for the disk to be shrunk", err)
+	}
+
+	disk, err = s.client.GetInstanceDisk(ctx, instance.ID, disk.ID)
+	if err != nil {
+		return handleError("Failed to get disk", err)
+	}
+
+	state.Put("disk", disk)
+	return multistep.ActionContinue
+}
+
+func (s *stepShrinkDisk) Cleanup(state multistep.StateBag) {}
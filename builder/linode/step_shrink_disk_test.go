@@ -0,0 +1,47 @@
+package linode
+
+import "testing"
+
+func TestParseDiskUsage(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected int
+		wantErr  bool
+	}{
+		{
+			name: "gnu df",
+			output: "Filesystem     1048576-blocks  Used Available Capacity Mounted on\n" +
+				"/dev/sda                25071  1873     21901       8% /\n",
+			expected: 1873,
+		},
+		{
+			name:    "no filesystem line",
+			output:  "Filesystem     1048576-blocks  Used Available Capacity Mounted on\n",
+			wantErr: true,
+		},
+		{
+			name:    "not a number",
+			output:  "Filesystem 1048576-blocks Used\n/dev/sda 25071 -\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used, err := parseDiskUsage(tt.output)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if used != tt.expected {
+				t.Errorf("got %d, expected %d", used, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+//go:generate packer-sdc struct-markdown
+//go:generate packer-sdc mapstructure-to-hcl2 -type TemporaryVPC
+package linode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"strings"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+	"github.com/linode/packer-plugin-linode/helper"
+)
+
+// defaultTemporarySubnetCIDR is the IPv4 range of the temporary subnet when
+// subnet_cidr is not set.
+const defaultTemporarySubnetCIDR = "10.0.0.0/24"
+
+// vpcLabelMaxLength is the maximum length of VPC and subnet labels.
+const vpcLabelMaxLength = 64
+
+var (
+	// vpcLabelRe matches valid VPC and subnet labels.
+	vpcLabelRe = regexp.MustCompile(`^[A-Za-z0-9]+(-[A-Za-z0-9]+)*$`)
+
+	// vpcLabelInvalidRe matches the characters not allowed in VPC labels.
+	vpcLabelInvalidRe = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// TemporaryVPC describes a VPC with a single subnet created in the build
+// region for the duration of the build.
+type TemporaryVPC struct {
+	// The label of the VPC and of its subnet. Defaults to `instance_label`,
+	// with the characters not allowed in VPC labels replaced by hyphens.
+	Label string `mapstructure:"label" required:"false"`
+
+	// The IPv4 range of the subnet in CIDR notation. Defaults to
+	// `10.0.0.0/24`.
+	SubnetCIDR string `mapstructure:"subnet_cidr" required:"false"`
+}
+
+// vpcLabel turns the instance label into a valid VPC label.
+func vpcLabel(instanceLabel string) string {
+	label := strings.Trim(vpcLabelInvalidRe.ReplaceAllString(instanceLabel, "-"), "-")
+	if len(label) > vpcLabelMaxLength {
+		label = strings.TrimRight(label[:vpcLabelMaxLength], "-")
+	}
+	return label
+}
+
+// prepare sets the defaults of the temporary_vpc block and validates it.
+func (v *TemporaryVPC) prepare(instanceLabel string) []error {
+	var errs []error
+
+	if v.Label == "" {
+		v.Label = vpcLabel(instanceLabel)
+	}
+	if v.SubnetCIDR == "" {
+		v.SubnetCIDR = defaultTemporarySubnetCIDR
+	}
+
+	if len(v.Label) > vpcLabelMaxLength || !vpcLabelRe.MatchString(v.Label) {
+		errs = append(errs, fmt.Errorf(
+			"temporary_vpc: label must be 1 to %d alphanumeric characters, separated by single hyphens",
+			vpcLabelMaxLength))
+	}
+
+	ip, _, err := net.ParseCIDR(v.SubnetCIDR)
+	switch {
+	case err != nil:
+		errs = append(errs, fmt.Errorf("temporary_vpc: invalid subnet_cidr: %w", err))
+	case ip.To4() == nil:
+		errs = append(errs, errors.New("temporary_vpc: subnet_cidr must be an IPv4 range"))
+	}
+
+	return errs
+}
+
+// validateTemporaryVPC validates the options that conflict with the
+// temporary_vpc block, as the builder generates the interfaces of the Linode.
+func (c *Config) validateTemporaryVPC() []error {
+	if c.TemporaryVPC == nil {
+		return nil
+	}
+
+	errs := c.TemporaryVPC.prepare(c.Label)
+
+	if len(c.Interfaces) > 0 || len(c.LinodeInterfaces) > 0 {
+		errs = append(errs, errors.New(
+			"temporary_vpc cannot be specified with interface or linode_interface blocks"))
+	}
+	if len(c.Disks) > 0 && !usesLinodeInterfaces(c) {
+		errs = append(errs, errors.New(
+			"temporary_vpc requires interface_generation to be linode when using disk blocks"))
+	}
+
+	return errs
+}
+
+// usesLinodeInterfaces returns whether the Linode is created with Linode
+// interfaces rather than legacy configuration profile interfaces.
+func usesLinodeInterfaces(c *Config) bool {
+	return linodego.InterfaceGeneration(c.InterfaceGeneration) == linodego.GenerationLinode
+}
+
+// temporaryVPCInterfaces returns the interface connecting the Linode to the
+// temporary subnet, with a 1:1 NAT address for SSH to be reachable. The
+// subnet ID is set by setTemporarySubnet once the subnet exists.
+func temporaryVPCInterfaces(c *Config) (
+	[]linodego.InstanceConfigInterfaceCreateOptions,
+	[]linodego.LinodeInterfaceCreateOptions,
+) {
+	if usesLinodeInterfaces(c) {
+		return nil, []linodego.LinodeInterfaceCreateOptions{{
+			DefaultRoute: &linodego.InterfaceDefaultRoute{
+				IPv4: linodego.Pointer(true),
+			},
+			VPC: &linodego.VPCInterfaceCreateOptions{
+				IPv4: &linodego.VPCInterfaceIPv4CreateOptions{
+					Addresses: linodego.Pointer([]linodego.VPCInterfaceIPv4AddressCreateOptions{{
+						Address:        linodego.Pointer("auto"),
+						Primary:        linodego.Pointer(true),
+						NAT1To1Address: linodego.Pointer("auto"),
+					}}),
+				},
+			},
+		}}
+	}
+
+	return []linodego.InstanceConfigInterfaceCreateOptions{{
+		Purpose: linodego.InterfacePurposeVPC,
+		Primary: true,
+		IPv4: &linodego.VPCIPv4{
+			NAT1To1: linodego.Pointer("any"),
+		},
+	}}, nil
+}
+
+// setTemporarySubnet points the VPC interfaces of the Linode to the
+// temporary subnet.
+func setTemporarySubnet(opts *linodego.InstanceCreateOptions, subnetID int) {
+	for i := range opts.Interfaces {
+		if opts.Interfaces[i].Purpose == linodego.InterfacePurposeVPC {
+			opts.Interfaces[i].SubnetID = linodego.Pointer(subnetID)
+		}
+	}
+	for i := range opts.LinodeInterfaces {
+		if opts.LinodeInterfaces[i].VPC != nil {
+			opts.LinodeInterfaces[i].VPC.SubnetID = subnetID
+		}
+	}
+}
+
+// temporarySubnet returns the ID of the temporary subnet in the region,
+// creating the temporary VPC if needed. The IDs of the created VPC and subnet
+// are recorded so that they are deleted during cleanup.
+func (s *stepCreateLinode) temporarySubnet(ctx context.Context, c *Config, region string) (int, error) {
+	if s.vpcID != 0 {
+		return s.subnetID, nil
+	}
+
+	vpc, err := s.client.CreateVPC(ctx, linodego.VPCCreateOptions{
+		Label: c.TemporaryVPC.Label,
+		// VPCs cannot be tagged, so the marker is the description instead
+		Description: BuildMarkerTag,
+		Region:      region,
+		Subnets: []linodego.VPCSubnetCreateOptions{{
+			Label: c.TemporaryVPC.Label,
+			IPv4:  c.TemporaryVPC.SubnetCIDR,
+		}},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create VPC: %w", err)
+	}
+	s.vpcID = vpc.ID
+
+	if len(vpc.Subnets) == 0 {
+		return 0, fmt.Errorf("VPC %d was created without a subnet", vpc.ID)
+	}
+	s.subnetID = vpc.Subnets[0].ID
+
+	return s.subnetID, nil
+}
+
+// deleteTemporaryVPC deletes the temporary subnet and VPC, retrying while
+// the deleted Linode still holds an address in the subnet, for at most
+// cleanup_timeout.
+func (s *stepCreateLinode) deleteTemporaryVPC(ui packersdk.Ui, c *Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.CleanupTimeout)
+	defer cancel()
+
+	ui.Say(fmt.Sprintf("Deleting VPC %d...", s.vpcID))
+
+	if s.subnetID != 0 {
+		err := helper.RetryWithBackoff(ctx, func() error {
+			err := s.client.DeleteVPCSubnet(ctx, s.vpcID, s.subnetID)
+			if err != nil && !linodego.IsNotFound(err) {
+				log.Printf("[WARN] Failed to delete VPC subnet %d, retrying: %s", s.subnetID, err)
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete VPC subnet %d: %w", s.subnetID, err)
+		}
+	}
+
+	err := helper.RetryWithBackoff(ctx, func() error {
+		err := s.client.DeleteVPC(ctx, s.vpcID)
+		if err != nil && !linodego.IsNotFound(err) {
+			log.Printf("[WARN] Failed to delete VPC %d, retrying: %s", s.vpcID, err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete VPC %d: %w", s.vpcID, err)
+	}
+	return nil
+}
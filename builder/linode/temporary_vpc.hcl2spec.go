@@ -0,0 +1,33 @@
+// Code generated by "packer-sdc mapstructure-to-hcl2"; DO NOT EDIT.
+
+package linode
+
+import (
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// FlatTemporaryVPC is an auto-generated flat version of TemporaryVPC.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatTemporaryVPC struct {
+	Label      *string `mapstructure:"label" required:"false" cty:"label" hcl:"label"`
+	SubnetCIDR *string `mapstructure:"subnet_cidr" required:"false" cty:"subnet_cidr" hcl:"subnet_cidr"`
+}
+
+// FlatMapstructure returns a new FlatTemporaryVPC.
+// FlatTemporaryVPC is an auto-generated flat version of TemporaryVPC.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*TemporaryVPC) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatTemporaryVPC)
+}
+
+// HCL2Spec returns the hcl spec of a TemporaryVPC.
+// This spec is used by HCL to read the fields of TemporaryVPC.
+// The decoded values from this spec will then be applied to a FlatTemporaryVPC.
+func (*FlatTemporaryVPC) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"label":       &hcldec.AttrSpec{Name: "label", Type: cty.String, Required: false},
+		"subnet_cidr": &hcldec.AttrSpec{Name: "subnet_cidr", Type: cty.String, Required: false},
+	}
+	return s
+}
@@ -0,0 +1,63 @@
+package linode
+
+import (
+	"testing"
+
+	"github.com/linode/linodego"
+)
+
+func TestVPCLabel(t *testing.T) {
+	tests := map[string]string{
+		"packer-1700000000": "packer-1700000000",
+		"my build@2024":     "my-build-2024",
+		"_packer__build_":   "packer-build",
+	}
+
+	for label, expected := range tests {
+		if got := vpcLabel(label); got != expected {
+			t.Errorf("%q: got %q, expected %q", label, got, expected)
+		}
+	}
+}
+
+func TestTemporaryVPCInterfaces(t *testing.T) {
+	c := &Config{TemporaryVPC: &TemporaryVPC{}}
+
+	interfaces, linodeInterfaces := temporaryVPCInterfaces(c)
+	if len(interfaces) != 1 || len(linodeInterfaces) != 0 {
+		t.Fatalf("got %d legacy and %d linode interfaces, expected 1 legacy", len(interfaces), len(linodeInterfaces))
+	}
+
+	opts := linodego.InstanceCreateOptions{Interfaces: interfaces}
+	setTemporarySubnet(&opts, 42)
+
+	i := opts.Interfaces[0]
+	if i.Purpose != linodego.InterfacePurposeVPC || !i.Primary {
+		t.Errorf("got interface %#v, expected a primary VPC interface", i)
+	}
+	if i.SubnetID == nil || *i.SubnetID != 42 {
+		t.Errorf("got subnet ID %v, expected 42", i.SubnetID)
+	}
+	if i.IPv4 == nil || i.IPv4.NAT1To1 == nil || *i.IPv4.NAT1To1 != "any" {
+		t.Errorf("got IPv4 %#v, expected a 1:1 NAT address", i.IPv4)
+	}
+
+	c.InterfaceGeneration = string(linodego.GenerationLinode)
+
+	interfaces, linodeInterfaces = temporaryVPCInterfaces(c)
+	if len(interfaces) != 0 || len(linodeInterfaces) != 1 {
+		t.Fatalf("got %d legacy and %d linode interfaces, expected 1 linode", len(interfaces), len(linodeInterfaces))
+	}
+
+	opts = linodego.InstanceCreateOptions{LinodeInterfaces: linodeInterfaces}
+	setTemporarySubnet(&opts, 42)
+
+	vpc := opts.LinodeInterfaces[0].VPC
+	if vpc == nil || vpc.SubnetID != 42 {
+		t.Fatalf("got VPC %#v, expected subnet 42", vpc)
+	}
+	addresses := *vpc.IPv4.Addresses
+	if len(addresses) != 1 || addresses[0].NAT1To1Address == nil || *addresses[0].NAT1To1Address != "auto" {
+		t.Errorf("got addresses %#v, expected a 1:1 NAT address", addresses)
+	}
+}
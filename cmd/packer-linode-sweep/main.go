@@ -0,0 +1,247 @@
+// Command packer-linode-sweep finds the Linodes, volumes, images, firewalls
+// and VPCs left behind by Linode builds that could not clean up, such as
+// builds whose Packer process crashed, and optionally deletes them.
+//
+// Only the resources tagged with the build marker tag the builder applies to
+// its temporary resources, and to its images until the build succeeds, are
+// considered. VPCs cannot be tagged, so the temporary VPCs are recognized by
+// their description, set to the build marker tag. Temporary placement groups
+// can neither be tagged nor dated, and are not considered.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/linode/linodego"
+	"github.com/linode/packer-plugin-linode/builder/linode"
+	"github.com/linode/packer-plugin-linode/helper"
+)
+
+// resource is a leftover resource of a build.
+type resource struct {
+	kind    string
+	id      string
+	label   string
+	created time.Time
+	delete  func(context.Context) error
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "packer-linode-sweep:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("packer-linode-sweep", flag.ContinueOnError)
+	olderThan := flags.Duration("older-than", 24*time.Hour,
+		"only consider resources created at least this long ago")
+	deleteResources := flags.Bool("delete", false,
+		"delete the resources found instead of only listing them")
+	profile := flags.String("profile", "",
+		"the linode-cli profile to read the API token from")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *olderThan < 0 {
+		return errors.New("-older-than must not be negative")
+	}
+
+	// Credentials are resolved like the plugin's: LINODE_TOKEN, then the
+	// linode-cli configuration
+	common := helper.LinodeCommon{Profile: *profile}
+	if errs := common.Prepare(); len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if common.PersonalAccessToken == "" {
+		return fmt.Errorf("a Linode API token is required; set %s or configure a linode-cli profile",
+			helper.TokenEnvVar)
+	}
+
+	client, err := common.NewClient()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	resources, err := findResources(ctx, client, time.Now().Add(-*olderThan))
+	if err != nil {
+		return err
+	}
+
+	if len(resources) == 0 {
+		fmt.Fprintln(out, "No leftover resources found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tID\tLABEL\tCREATED")
+	for _, r := range resources {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.kind, r.id, r.label, r.created.Format(time.RFC3339))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if !*deleteResources {
+		fmt.Fprintln(out, "Run with -delete to delete these resources")
+		return nil
+	}
+
+	var errs []error
+	for _, r := range resources {
+		fmt.Fprintf(out, "Deleting %s %s...\n", r.kind, r.id)
+		if err := r.delete(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %s %s: %w", r.kind, r.id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// markerFilter returns the list options of the resources tagged with the
+// build marker tag.
+func markerFilter() (*linodego.ListOptions, error) {
+	filter := linodego.Filter{}
+	filter.AddField(linodego.Eq, "tags", linode.BuildMarkerTag)
+	filterString, err := filter.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return linodego.NewListOptions(0, string(filterString)), nil
+}
+
+// isLeftover reports whether a resource was left behind by a build: it must be
+// tagged with the build marker tag, whatever the API filter matched, and have
+// been created before the cutoff.
+func isLeftover(tags []string, created *time.Time, cutoff time.Time) bool {
+	return slices.Contains(tags, linode.BuildMarkerTag) && created != nil && created.Before(cutoff)
+}
+
+// findResources returns the leftover images, volumes, instances, firewalls
+// and VPCs created before the cutoff, in the order they are deleted in:
+// volumes before the instances they may still be attached to, and firewalls
+// and VPCs once the instances they hold are gone.
+func findResources(ctx context.Context, client *linodego.Client, cutoff time.Time) ([]resource, error) {
+	opts, err := markerFilter()
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []resource
+
+	images, err := client.ListImages(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+	for _, image := range images {
+		if image.IsPublic || !isLeftover(image.Tags, image.Created, cutoff) {
+			continue
+		}
+		resources = append(resources, resource{
+			kind:    "image",
+			id:      image.ID,
+			label:   image.Label,
+			created: *image.Created,
+			delete: func(ctx context.Context) error {
+				return client.DeleteImage(ctx, image.ID)
+			},
+		})
+	}
+
+	volumes, err := client.ListVolumes(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
+	}
+	for _, volume := range volumes {
+		if !isLeftover(volume.Tags, volume.Created, cutoff) {
+			continue
+		}
+		resources = append(resources, resource{
+			kind:    "volume",
+			id:      fmt.Sprint(volume.ID),
+			label:   volume.Label,
+			created: *volume.Created,
+			delete: func(ctx context.Context) error {
+				if volume.LinodeID != nil {
+					if err := client.DetachVolume(ctx, volume.ID); err != nil {
+						return err
+					}
+					if _, err := client.WaitForVolumeLinodeID(ctx, volume.ID, nil, 300); err != nil {
+						return err
+					}
+				}
+				return client.DeleteVolume(ctx, volume.ID)
+			},
+		})
+	}
+
+	instances, err := client.ListInstances(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Linodes: %w", err)
+	}
+	for _, instance := range instances {
+		if !isLeftover(instance.Tags, instance.Created, cutoff) {
+			continue
+		}
+		resources = append(resources, resource{
+			kind:    "instance",
+			id:      fmt.Sprint(instance.ID),
+			label:   instance.Label,
+			created: *instance.Created,
+			delete: func(ctx context.Context) error {
+				return client.DeleteInstance(ctx, instance.ID)
+			},
+		})
+	}
+
+	firewalls, err := client.ListFirewalls(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list firewalls: %w", err)
+	}
+	for _, firewall := range firewalls {
+		if !isLeftover(firewall.Tags, firewall.Created, cutoff) {
+			continue
+		}
+		resources = append(resources, resource{
+			kind:    "firewall",
+			id:      fmt.Sprint(firewall.ID),
+			label:   firewall.Label,
+			created: *firewall.Created,
+			delete: func(ctx context.Context) error {
+				return client.DeleteFirewall(ctx, firewall.ID)
+			},
+		})
+	}
+
+	// The API cannot filter VPCs by description
+	vpcs, err := client.ListVPCs(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VPCs: %w", err)
+	}
+	for _, vpc := range vpcs {
+		if vpc.Description != linode.BuildMarkerTag || vpc.Created == nil || !vpc.Created.Before(cutoff) {
+			continue
+		}
+		resources = append(resources, resource{
+			kind:    "vpc",
+			id:      fmt.Sprint(vpc.ID),
+			label:   vpc.Label,
+			created: *vpc.Created,
+			delete: func(ctx context.Context) error {
+				return client.DeleteVPC(ctx, vpc.ID)
+			},
+		})
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/linode/packer-plugin-linode/helper"
+)
+
+// newTestAPI serves leftover resources, ignoring the filters so that the
+// command's own checks are exercised, and records the deletions.
+func newTestAPI(t *testing.T) (*httptest.Server, *[]string) {
+	var (
+		mu      sync.Mutex
+		deleted []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodDelete {
+			mu.Lock()
+			deleted = append(deleted, r.URL.Path)
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+
+		var data string
+		switch r.URL.Path {
+		case "/v4/images":
+			data = `
+				{"id": "private/1", "label": "packer-1", "tags": ["packer-linode-build"], "created": "2024-01-01T00:00:00"},
+				{"id": "private/2", "label": "golden", "tags": [], "created": "2024-01-01T00:00:00"}`
+		case "/v4/volumes":
+			data = `
+				{"id": 10, "label": "scratch", "tags": ["packer-linode-build"], "created": "2024-01-01T00:00:00"}`
+		case "/v4/linode/instances":
+			data = `
+				{"id": 100, "label": "packer-100", "tags": ["packer-linode-build"], "created": "2024-01-01T00:00:00"},
+				{"id": 101, "label": "packer-101", "tags": ["packer-linode-build"], "created": "2999-01-01T00:00:00"},
+				{"id": 102, "label": "packer-102", "tags": ["web"], "created": "2024-01-01T00:00:00"}`
+		case "/v4/networking/firewalls":
+			data = `
+				{"id": 1000, "label": "packer-100", "tags": ["packer-linode-build"], "created": "2024-01-01T00:00:00"}`
+		case "/v4/vpcs":
+			data = `
+				{"id": 5, "label": "packer-vpc", "description": "packer-linode-build", "created": "2024-01-01T00:00:00"},
+				{"id": 6, "label": "production", "description": "", "created": "2024-01-01T00:00:00"}`
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"page": 1, "pages": 1, "data": [` + data + `]}`))
+	}))
+
+	return server, &deleted
+}
+
+func TestRun(t *testing.T) {
+	server, deleted := newTestAPI(t)
+	defer server.Close()
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv(helper.TokenEnvVar, "secret")
+	t.Setenv(helper.URLEnvVar, server.URL)
+	t.Setenv(helper.APIVersionEnvVar, "")
+
+	var out bytes.Buffer
+	if err := run([]string{"-older-than", "1h"}, &out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, s := range []string{"private/1", "scratch", "packer-100", "packer-vpc", "-delete"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("output should list %q:\n%s", s, out.String())
+		}
+	}
+	for _, s := range []string{"golden", "packer-101", "packer-102", "production"} {
+		if strings.Contains(out.String(), s) {
+			t.Errorf("output should not list %q:\n%s", s, out.String())
+		}
+	}
+	if len(*deleted) > 0 {
+		t.Fatalf("nothing should be deleted without -delete, got %v", *deleted)
+	}
+
+	out.Reset()
+	if err := run([]string{"-older-than", "1h", "-delete"}, &out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{
+		"/v4/images/private/1",
+		"/v4/volumes/10",
+		"/v4/linode/instances/100",
+		"/v4/networking/firewalls/1000",
+		"/v4/vpcs/5",
+	}
+	if !reflect.DeepEqual(*deleted, expected) {
+		t.Errorf("got deletions %v, expected %v", *deleted, expected)
+	}
+}
@@ -5,7 +5,6 @@ package image
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/hashicorp/hcl/v2/hcldec"
@@ -38,6 +37,9 @@ type Config struct {
 	// Matching the ID of an image by a regular expression
 	IDRegex string `mapstructure:"id_regex"`
 
+	// Matching images having all of the given tags
+	Tags []string `mapstructure:"tags"`
+
 	// Whether to use the latest created image when there are multiple matches
 	Latest bool `mapstructure:"latest"`
 }
@@ -54,17 +56,16 @@ func (d *Datasource) Configure(raws ...interface{}) error {
 
 	var errs *packersdk.MultiError
 
+	errs = packersdk.MultiErrorAppend(errs, d.config.LinodeCommon.Prepare()...)
+
 	if d.config.PersonalAccessToken == "" {
-		envToken := os.Getenv(helper.TokenEnvVar)
-		if envToken == "" {
-			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
-				"a Linode API token is required; you can specify it in an "+
-					"environment variable %q or set linode_token "+
-					"attribute in the datasource block",
-				helper.TokenEnvVar,
-			))
-		}
-		d.config.PersonalAccessToken = envToken
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+			"a Linode API token is required; you can specify it in an "+
+				"environment variable %q, set linode_token or "+
+				"linode_token_file attribute in the datasource block, "+
+				"or configure a linode-cli profile",
+			helper.TokenEnvVar,
+		))
 	}
 
 	if errs != nil && len(errs.Errors) > 0 {
@@ -108,6 +109,9 @@ type DatasourceOutput struct {
 	// A short description of the Image.
 	Label string `mapstructure:"label"`
 
+	// The tags of the Image.
+	Tags []string `mapstructure:"tags"`
+
 	// The minimum size this Image needs to deploy. Size is in MB.
 	Size int `mapstructure:"size"`
 
@@ -129,16 +133,9 @@ func (d *Datasource) OutputSpec() hcldec.ObjectSpec {
 }
 
 func (d *Datasource) Execute() (cty.Value, error) {
-	var client *linodego.Client
-	var err error
-
-	if d.config.APICAPath != "" {
-		client, err = helper.NewLinodeClientWithCA(d.config.PersonalAccessToken, d.config.APICAPath)
-		if err != nil {
-			return cty.NullVal(cty.EmptyObject), err
-		}
-	} else {
-		client = helper.NewLinodeClient(d.config.PersonalAccessToken)
+	client, err := d.config.NewClient()
+	if err != nil {
+		return cty.NullVal(cty.EmptyObject), err
 	}
 
 	filters := linodego.Filter{}
@@ -182,6 +179,7 @@ func getOutput(image linodego.Image) DatasourceOutput {
 		Description:  image.Description,
 		IsPublic:     image.IsPublic,
 		Label:        image.Label,
+		Tags:         image.Tags,
 		Size:         image.Size,
 		Type:         image.Type,
 		Vendor:       image.Vendor,
@@ -19,11 +19,19 @@ type FlatConfig struct {
 	PackerUserVars      map[string]string `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
 	PackerSensitiveVars []string          `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
 	PersonalAccessToken *string           `mapstructure:"linode_token" cty:"linode_token" hcl:"linode_token"`
+	TokenFile           *string           `mapstructure:"linode_token_file" cty:"linode_token_file" hcl:"linode_token_file"`
+	ConfigPath          *string           `mapstructure:"linode_config_path" cty:"linode_config_path" hcl:"linode_config_path"`
+	Profile             *string           `mapstructure:"linode_profile" cty:"linode_profile" hcl:"linode_profile"`
 	APICAPath           *string           `mapstructure:"api_ca_path" cty:"api_ca_path" hcl:"api_ca_path"`
+	APIURL              *string           `mapstructure:"api_url" cty:"api_url" hcl:"api_url"`
+	APIVersion          *string           `mapstructure:"api_version" cty:"api_version" hcl:"api_version"`
+	APIMaxRetries       *int              `mapstructure:"api_max_retries" cty:"api_max_retries" hcl:"api_max_retries"`
+	APIRetryMaxWait     *string           `mapstructure:"api_retry_max_wait" cty:"api_retry_max_wait" hcl:"api_retry_max_wait"`
 	Label               *string           `mapstructure:"label" cty:"label" hcl:"label"`
 	LabelRegex          *string           `mapstructure:"label_regex" cty:"label_regex" hcl:"label_regex"`
 	ID                  *string           `mapstructure:"id" cty:"id" hcl:"id"`
 	IDRegex             *string           `mapstructure:"id_regex" cty:"id_regex" hcl:"id_regex"`
+	Tags                []string          `mapstructure:"tags" cty:"tags" hcl:"tags"`
 	Latest              *bool             `mapstructure:"latest" cty:"latest" hcl:"latest"`
 }
 
@@ -48,11 +56,19 @@ func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
 		"packer_user_variables":      &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
 		"packer_sensitive_variables": &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
 		"linode_token":               &hcldec.AttrSpec{Name: "linode_token", Type: cty.String, Required: false},
+		"linode_token_file":          &hcldec.AttrSpec{Name: "linode_token_file", Type: cty.String, Required: false},
+		"linode_config_path":         &hcldec.AttrSpec{Name: "linode_config_path", Type: cty.String, Required: false},
+		"linode_profile":             &hcldec.AttrSpec{Name: "linode_profile", Type: cty.String, Required: false},
 		"api_ca_path":                &hcldec.AttrSpec{Name: "api_ca_path", Type: cty.String, Required: false},
+		"api_url":                    &hcldec.AttrSpec{Name: "api_url", Type: cty.String, Required: false},
+		"api_version":                &hcldec.AttrSpec{Name: "api_version", Type: cty.String, Required: false},
+		"api_max_retries":            &hcldec.AttrSpec{Name: "api_max_retries", Type: cty.Number, Required: false},
+		"api_retry_max_wait":         &hcldec.AttrSpec{Name: "api_retry_max_wait", Type: cty.String, Required: false},
 		"label":                      &hcldec.AttrSpec{Name: "label", Type: cty.String, Required: false},
 		"label_regex":                &hcldec.AttrSpec{Name: "label_regex", Type: cty.String, Required: false},
 		"id":                         &hcldec.AttrSpec{Name: "id", Type: cty.String, Required: false},
 		"id_regex":                   &hcldec.AttrSpec{Name: "id_regex", Type: cty.String, Required: false},
+		"tags":                       &hcldec.AttrSpec{Name: "tags", Type: cty.List(cty.String), Required: false},
 		"latest":                     &hcldec.AttrSpec{Name: "latest", Type: cty.Bool, Required: false},
 	}
 	return s
@@ -71,6 +87,7 @@ type FlatDatasourceOutput struct {
 	Expiry       *string  `mapstructure:"expiry" cty:"expiry" hcl:"expiry"`
 	IsPublic     *bool    `mapstructure:"is_public" cty:"is_public" hcl:"is_public"`
 	Label        *string  `mapstructure:"label" cty:"label" hcl:"label"`
+	Tags         []string `mapstructure:"tags" cty:"tags" hcl:"tags"`
 	Size         *int     `mapstructure:"size" cty:"size" hcl:"size"`
 	Type         *string  `mapstructure:"type" cty:"type" hcl:"type"`
 	Updated      *string  `mapstructure:"updated" cty:"updated" hcl:"updated"`
@@ -99,6 +116,7 @@ func (*FlatDatasourceOutput) HCL2Spec() map[string]hcldec.Spec {
 		"expiry":       &hcldec.AttrSpec{Name: "expiry", Type: cty.String, Required: false},
 		"is_public":    &hcldec.AttrSpec{Name: "is_public", Type: cty.Bool, Required: false},
 		"label":        &hcldec.AttrSpec{Name: "label", Type: cty.String, Required: false},
+		"tags":         &hcldec.AttrSpec{Name: "tags", Type: cty.List(cty.String), Required: false},
 		"size":         &hcldec.AttrSpec{Name: "size", Type: cty.Number, Required: false},
 		"type":         &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
 		"updated":      &hcldec.AttrSpec{Name: "updated", Type: cty.String, Required: false},
@@ -3,6 +3,7 @@ package image
 import (
 	"errors"
 	"regexp"
+	"slices"
 	"sort"
 
 	"github.com/linode/linodego"
@@ -37,7 +38,9 @@ func filterImagesByIDRegex(images []linodego.Image, idRegex string) []linodego.I
 	return filterImages(images, idRegexFilter)
 }
 
-func filterImagesByLabelRegex(images []linodego.Image, labelRegex string) []linodego.Image {
+// FilterImagesByLabelRegex returns the images whose label matches the
+// regular expression.
+func FilterImagesByLabelRegex(images []linodego.Image, labelRegex string) []linodego.Image {
 	r := regexp.MustCompile(labelRegex)
 	labelRegexFilter := func(image linodego.Image) bool {
 		return r.MatchString(image.Label)
@@ -45,9 +48,22 @@ func filterImagesByLabelRegex(images []linodego.Image, labelRegex string) []lino
 	return filterImages(images, labelRegexFilter)
 }
 
+// FilterImagesByTags returns the images having all of the given tags.
+func FilterImagesByTags(images []linodego.Image, tags []string) []linodego.Image {
+	tagsFilter := func(image linodego.Image) bool {
+		for _, tag := range tags {
+			if !slices.Contains(image.Tags, tag) {
+				return false
+			}
+		}
+		return true
+	}
+	return filterImages(images, tagsFilter)
+}
+
 func filterImageResults(images []linodego.Image, config Config) (linodego.Image, error) {
 	if config.LabelRegex != "" {
-		images = filterImagesByLabelRegex(images, config.LabelRegex)
+		images = FilterImagesByLabelRegex(images, config.LabelRegex)
 	}
 	if config.ID != "" {
 		images = filterImagesByID(images, config.ID)
@@ -55,6 +71,9 @@ func filterImageResults(images []linodego.Image, config Config) (linodego.Image,
 	if config.IDRegex != "" {
 		images = filterImagesByIDRegex(images, config.IDRegex)
 	}
+	if len(config.Tags) > 0 {
+		images = FilterImagesByTags(images, config.Tags)
+	}
 	if len(images) > 1 {
 
 		if config.Latest {
@@ -78,3 +78,30 @@ func TestImageDatasourceFilter_LabelRegexFilter(t *testing.T) {
 		)
 	}
 }
+
+func TestImageDatasourceFilter_TagsFilter(t *testing.T) {
+	images := []linodego.Image{
+		{ID: "private/1", Tags: []string{"team=platform"}},
+		{ID: "private/2", Tags: []string{"team=platform", "os=ubuntu"}},
+		{ID: "private/3", Tags: []string{"os=ubuntu"}},
+	}
+
+	config := Config{Tags: []string{"os=ubuntu", "team=platform"}}
+
+	image, err := filterImageResults(images, config)
+	if err != nil {
+		t.Fatalf("error filtering by image tags: %v", err)
+	}
+	if image.ID != "private/2" {
+		t.Fatalf(
+			"incorrect image with ID '%q' got selected, image "+
+				"with ID '%q' should be selected instead",
+			image.ID, "private/2",
+		)
+	}
+
+	config = Config{Tags: []string{"os=debian"}}
+	if _, err := filterImageResults(images, config); err == nil {
+		t.Fatal("expected no image to be found")
+	}
+}
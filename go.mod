@@ -12,6 +12,7 @@ require (
 	github.com/zclconf/go-cty v1.16.3
 	golang.org/x/crypto v0.51.0
 	golang.org/x/oauth2 v0.36.0
+	gopkg.in/ini.v1 v1.67.2
 )
 
 require (
@@ -147,7 +148,6 @@ require (
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20260311181403-84a4fc48630c // indirect
 	google.golang.org/grpc v1.79.3 // indirect
 	google.golang.org/protobuf v1.36.11 // indirect
-	gopkg.in/ini.v1 v1.67.2 // indirect
 )
 
 // Temporary replacement for issue causing releases to fail (see: #379)
@@ -13,7 +13,11 @@ import (
 	"golang.org/x/oauth2"
 )
 
-const TokenEnvVar = "LINODE_TOKEN"
+const (
+	TokenEnvVar      = "LINODE_TOKEN"
+	URLEnvVar        = "LINODE_URL"
+	APIVersionEnvVar = "LINODE_API_VERSION"
+)
 
 // AddRootCAToTransport applies the CA at the given path to the given *http.Transport
 func AddRootCAToTransport(CAPath string, transport *http.Transport) error {
@@ -49,6 +53,9 @@ func linodeClientFromTransport(transport http.RoundTripper) *linodego.Client {
 		version.PluginVersion.FormattedVersion(), projectURL, linodego.Version)
 
 	client.SetUserAgent(userAgent)
+
+	// Retries are handled by the transport, according to the retry policy
+	client.SetRetryCount(0)
 	return &client
 }
 
@@ -66,16 +73,35 @@ func getOauth2TransportWithToken(token string, baseTransport http.RoundTripper)
 	return oauthTransport
 }
 
+// defaultRetryPolicy is the retry policy of the clients created without one.
+var defaultRetryPolicy = RetryPolicy{
+	MaxRetries: DefaultAPIMaxRetries,
+	MaxWait:    DefaultAPIRetryMaxWait,
+}
+
 func NewLinodeClient(token string) *linodego.Client {
-	oauthTransport := getOauth2TransportWithToken(token, nil)
-	return linodeClientFromTransport(oauthTransport)
+	return NewLinodeClientWithRetry(token, defaultRetryPolicy)
 }
 
 func NewLinodeClientWithCA(token, CAPath string) (*linodego.Client, error) {
+	return NewLinodeClientWithCAAndRetry(token, CAPath, defaultRetryPolicy)
+}
+
+// NewLinodeClientWithRetry returns a Linode API client retrying the failed
+// requests according to the given policy.
+func NewLinodeClientWithRetry(token string, retry RetryPolicy) *linodego.Client {
+	oauthTransport := getOauth2TransportWithToken(token, newRetryTransport(nil, retry))
+	return linodeClientFromTransport(oauthTransport)
+}
+
+// NewLinodeClientWithCAAndRetry returns a Linode API client trusting the CA
+// at the given path and retrying the failed requests according to the given
+// policy.
+func NewLinodeClientWithCAAndRetry(token, CAPath string, retry RetryPolicy) (*linodego.Client, error) {
 	transport, err := getDefaultTransportWithCA(CAPath)
 	if err != nil {
 		return nil, err
 	}
-	oauthTransport := getOauth2TransportWithToken(token, transport)
+	oauthTransport := getOauth2TransportWithToken(token, newRetryTransport(transport, retry))
 	return linodeClientFromTransport(oauthTransport), nil
 }
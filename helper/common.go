@@ -1,17 +1,144 @@
 //go:generate packer-sdc struct-markdown
 package helper
 
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/linode/linodego"
+)
+
 // The common configuration options related to Linode services
 type LinodeCommon struct {
 	// The Linode API token required for provision Linode resources.
-	// This can also be specified in `LINODE_TOKEN` environment variable.
 	// Saving the token in the environment or centralized vaults
 	// can reduce the risk of the token being leaked from the codebase.
 	// `images:read_write`, `linodes:read_write`, and `events:read_only`
 	// scopes are required for the API token.
+	//
+	// The token is resolved in the following order: `linode_token`,
+	// `linode_token_file`, the `LINODE_TOKEN` environment variable, then the
+	// `token` of the default linode-cli profile. When `linode_config_path` or
+	// `linode_profile` is set, the `token` of the selected profile takes
+	// precedence over the `LINODE_TOKEN` environment variable, and a profile
+	// without a token but with an `api_url` or `api_version` cannot be
+	// combined with the environment variable.
 	PersonalAccessToken string `mapstructure:"linode_token"`
 
+	// The path to a file containing the Linode API token, e.g. a secret
+	// mounted by a CI system. Surrounding whitespace is ignored.
+	TokenFile string `mapstructure:"linode_token_file"`
+
+	// The path to a linode-cli configuration file to read the token, and the
+	// `api_url` and `api_version`, of a profile from. Defaults to the first of
+	// `$XDG_CONFIG_HOME/linode-cli`, `~/.config/linode-cli` and
+	// `~/.config/linode` that exists. The file is only read when it is the
+	// only source of the token, or when `linode_config_path` or
+	// `linode_profile` is set.
+	ConfigPath string `mapstructure:"linode_config_path"`
+
+	// The profile of the linode-cli configuration file to use. Defaults to the
+	// `default-user` of the configuration file, or the `default` profile.
+	Profile string `mapstructure:"linode_profile"`
+
 	// The path to a CA file to trust when making API requests.
 	// It can also be specified using the `LINODE_CA` environment variable.
 	APICAPath string `mapstructure:"api_ca_path"`
+
+	// The base URL of the Linode API, without the API version, e.g. an
+	// internal proxy or a local mock server. It can also be specified using
+	// the `LINODE_URL` environment variable, or by the `api_url` of the
+	// linode-cli profile. When the token is read from a profile selected with
+	// `linode_config_path` or `linode_profile`, the `api_url` of the profile
+	// is used instead of the environment variable. Defaults to
+	// `https://api.linode.com`.
+	APIURL string `mapstructure:"api_url"`
+
+	// The version of the Linode API to use, e.g. `v4beta`. It can also be
+	// specified using the `LINODE_API_VERSION` environment variable, or by the
+	// `api_version` of the linode-cli profile, with the same precedence as
+	// `api_url`. Defaults to `v4`.
+	APIVersion string `mapstructure:"api_version"`
+
+	// The number of times an API request that failed because of rate limiting
+	// (HTTP 429) or a transient error is retried, with exponential backoff.
+	// The `Retry-After` header of the API is respected. Requests that may
+	// have been processed, such as creations that failed with a server error,
+	// are never retried. Defaults to `5`. Set to `0` to disable retries.
+	APIMaxRetries *int `mapstructure:"api_max_retries"`
+
+	// The maximum time, as a duration string, spent on a failed API request
+	// across all of its retries, including the time spent in the attempts.
+	// A request is not retried once this time would be exceeded by waiting
+	// for the next attempt. Defaults to `2m`.
+	APIRetryMaxWait time.Duration `mapstructure:"api_retry_max_wait"`
+}
+
+// Prepare sets the defaults of the common options and validates them.
+func (c *LinodeCommon) Prepare() []error {
+	var errs []error
+
+	errs = append(errs, c.prepareCredentials()...)
+
+	if c.APIURL != "" {
+		if u, err := url.Parse(c.APIURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("api_url must be an http or https URL, got %q", c.APIURL))
+		}
+	}
+
+	if c.APIMaxRetries == nil {
+		maxRetries := DefaultAPIMaxRetries
+		c.APIMaxRetries = &maxRetries
+	} else if *c.APIMaxRetries < 0 {
+		errs = append(errs, errors.New("api_max_retries must not be negative"))
+	}
+
+	if c.APIRetryMaxWait == 0 {
+		c.APIRetryMaxWait = DefaultAPIRetryMaxWait
+	} else if c.APIRetryMaxWait < 0 {
+		errs = append(errs, errors.New("api_retry_max_wait must not be negative"))
+	}
+
+	return errs
+}
+
+// RetryPolicy returns the retry policy of the API client.
+func (c *LinodeCommon) RetryPolicy() RetryPolicy {
+	policy := RetryPolicy{
+		MaxRetries: DefaultAPIMaxRetries,
+		MaxWait:    c.APIRetryMaxWait,
+	}
+	if c.APIMaxRetries != nil {
+		policy.MaxRetries = *c.APIMaxRetries
+	}
+	if policy.MaxWait == 0 {
+		policy.MaxWait = DefaultAPIRetryMaxWait
+	}
+	return policy
+}
+
+// NewClient returns a Linode API client configured with the common options.
+func (c *LinodeCommon) NewClient() (*linodego.Client, error) {
+	var client *linodego.Client
+
+	if c.APICAPath != "" {
+		var err error
+		client, err = NewLinodeClientWithCAAndRetry(c.PersonalAccessToken, c.APICAPath, c.RetryPolicy())
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		client = NewLinodeClientWithRetry(c.PersonalAccessToken, c.RetryPolicy())
+	}
+
+	if c.APIURL != "" {
+		client.SetBaseURL(c.APIURL)
+	}
+	if c.APIVersion != "" {
+		client.SetAPIVersion(c.APIVersion)
+	}
+
+	return client, nil
 }
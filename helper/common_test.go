@@ -0,0 +1,102 @@
+package helper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLinodeCommonPrepare(t *testing.T) {
+	isolateCredentials(t)
+
+	var c LinodeCommon
+	if errs := c.Prepare(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	expected := RetryPolicy{MaxRetries: DefaultAPIMaxRetries, MaxWait: DefaultAPIRetryMaxWait}
+	if got := c.RetryPolicy(); got != expected {
+		t.Errorf("got %#v, expected %#v", got, expected)
+	}
+
+	disabled := 0
+	c = LinodeCommon{APIMaxRetries: &disabled, APIRetryMaxWait: 10 * time.Second}
+	if errs := c.Prepare(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if got := c.RetryPolicy(); got.MaxRetries != 0 || got.MaxWait != 10*time.Second {
+		t.Errorf("got %#v", got)
+	}
+
+	negative := -1
+	c = LinodeCommon{APIMaxRetries: &negative, APIRetryMaxWait: -time.Second}
+	if errs := c.Prepare(); len(errs) != 2 {
+		t.Errorf("got %v, expected 2 errors", errs)
+	}
+}
+
+func TestLinodeCommonPrepare_API(t *testing.T) {
+	isolateCredentials(t)
+	t.Setenv(URLEnvVar, "http://localhost:8080")
+	t.Setenv(APIVersionEnvVar, "v4beta")
+
+	var c LinodeCommon
+	if errs := c.Prepare(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if c.APIURL != "http://localhost:8080" || c.APIVersion != "v4beta" {
+		t.Errorf("got api_url %q and api_version %q from the environment", c.APIURL, c.APIVersion)
+	}
+
+	c = LinodeCommon{APIURL: "https://api.example.com"}
+	if errs := c.Prepare(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if c.APIURL != "https://api.example.com" {
+		t.Errorf("api_url should take precedence over the environment, got %q", c.APIURL)
+	}
+
+	for _, apiURL := range []string{"api.linode.com", "ftp://api.linode.com", "https://"} {
+		c = LinodeCommon{APIURL: apiURL}
+		if errs := c.Prepare(); len(errs) != 1 {
+			t.Errorf("%q: got %v, expected 1 error", apiURL, errs)
+		}
+	}
+}
+
+func TestLinodeCommonNewClient(t *testing.T) {
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("got Authorization %q", auth)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"username": "packer"}`))
+	}))
+	defer server.Close()
+
+	c := LinodeCommon{
+		PersonalAccessToken: "secret",
+		APIURL:              server.URL,
+		APIVersion:          "v4beta",
+	}
+
+	client, err := c.NewClient()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	profile, err := client.GetProfile(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if profile.Username != "packer" {
+		t.Errorf("got username %q", profile.Username)
+	}
+	if requested != "/v4beta/profile" {
+		t.Errorf("got path %q, expected /v4beta/profile", requested)
+	}
+}
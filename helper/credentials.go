@@ -0,0 +1,203 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+const (
+	// linodeCLIDefaultUserKey is the key of the DEFAULT section of linode-cli
+	// configuration files naming the default profile.
+	linodeCLIDefaultUserKey = "default-user"
+
+	defaultProfile = "default"
+)
+
+// configProfile is a profile of a linode-cli configuration file.
+type configProfile struct {
+	token      string
+	apiURL     string
+	apiVersion string
+}
+
+// defaultConfigPaths returns the paths linode-cli configuration files are
+// looked up at, in order.
+func defaultConfigPaths() []string {
+	var paths []string
+
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		paths = append(paths, filepath.Join(dir, "linode-cli"))
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths,
+			filepath.Join(home, ".config", "linode-cli"),
+			filepath.Join(home, ".config", "linode"),
+		)
+	}
+
+	return paths
+}
+
+// findConfigFile returns the first linode-cli configuration file that
+// exists, or an empty string if there is none.
+func findConfigFile() string {
+	for _, path := range defaultConfigPaths() {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// loadConfigProfile reads a profile of a linode-cli configuration file. When
+// name is empty, the profile named by the default-user key of the DEFAULT
+// section is read, falling back to the profile named default.
+func loadConfigProfile(path, name string) (*configProfile, error) {
+	cfg, err := ini.Load(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Linode config file %s: %w", path, err)
+	}
+
+	if name == "" {
+		name = cfg.Section(ini.DefaultSection).Key(linodeCLIDefaultUserKey).String()
+	}
+	if name == "" {
+		name = defaultProfile
+	}
+
+	section, err := cfg.GetSection(name)
+	if err != nil {
+		return nil, fmt.Errorf("profile %q not found in Linode config file %s", name, path)
+	}
+
+	profile := &configProfile{
+		token:      section.Key("token").String(),
+		apiURL:     section.Key("api_url").String(),
+		apiVersion: section.Key("api_version").String(),
+	}
+
+	// linode-cli stores the API host and scheme separately
+	if host := section.Key("api_host").String(); profile.apiURL == "" && host != "" {
+		scheme := section.Key("api_scheme").MustString("https")
+		profile.apiURL = scheme + "://" + host
+	}
+
+	return profile, nil
+}
+
+// readTokenFile returns the token stored in the given file.
+func readTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to read linode_token_file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", errors.New("linode_token_file is empty")
+	}
+	return token, nil
+}
+
+// prepareCredentials resolves the API token, URL and version. An explicitly
+// selected profile takes precedence over the environment variables, so that
+// the token of one environment is never sent to the API of another.
+// Otherwise, the environment variables take precedence over the profile.
+func (c *LinodeCommon) prepareCredentials() []error {
+	var errs []error
+
+	// Fills the API URL and version left unset by the options and profile
+	useEnvironment := true
+	defer func() {
+		if useEnvironment {
+			c.applyAPIEnvironment()
+		}
+	}()
+
+	if c.PersonalAccessToken == "" && c.TokenFile != "" {
+		token, err := readTokenFile(c.TokenFile)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		c.PersonalAccessToken = token
+	}
+
+	var envToken string
+	if c.PersonalAccessToken == "" {
+		envToken = os.Getenv(TokenEnvVar)
+	}
+
+	// The configuration file is read when it is the only source of the token,
+	// or when a profile is explicitly selected
+	explicit := c.ConfigPath != "" || c.Profile != ""
+	if !explicit && (c.PersonalAccessToken != "" || envToken != "") {
+		if c.PersonalAccessToken == "" {
+			c.PersonalAccessToken = envToken
+		}
+		return errs
+	}
+
+	path := c.ConfigPath
+	if path == "" {
+		path = findConfigFile()
+	}
+	if path == "" {
+		if c.Profile != "" {
+			errs = append(errs, fmt.Errorf("linode_profile %q is set but no Linode config file was found", c.Profile))
+		}
+		return errs
+	}
+
+	profile, err := loadConfigProfile(path, c.Profile)
+	if err != nil {
+		if explicit {
+			errs = append(errs, err)
+		}
+		return errs
+	}
+
+	if !explicit {
+		c.applyAPIEnvironment()
+	}
+
+	if c.PersonalAccessToken == "" {
+		switch {
+		case profile.token != "":
+			c.PersonalAccessToken = profile.token
+
+			// The token of the profile is only sent to the API of the profile
+			useEnvironment = !explicit
+		case envToken != "" && (profile.apiURL != "" || profile.apiVersion != ""):
+			errs = append(errs, fmt.Errorf(
+				"the selected Linode profile has no token, and the %s environment variable cannot be "+
+					"used with the api_url or api_version of the profile", TokenEnvVar))
+		default:
+			c.PersonalAccessToken = envToken
+		}
+	}
+	if c.APIURL == "" {
+		c.APIURL = profile.apiURL
+	}
+	if c.APIVersion == "" {
+		c.APIVersion = profile.apiVersion
+	}
+
+	return errs
+}
+
+// applyAPIEnvironment sets the API URL and version from the environment
+// variables, unless they are already set.
+func (c *LinodeCommon) applyAPIEnvironment() {
+	if c.APIURL == "" {
+		c.APIURL = os.Getenv(URLEnvVar)
+	}
+	if c.APIVersion == "" {
+		c.APIVersion = os.Getenv(APIVersionEnvVar)
+	}
+}
@@ -0,0 +1,201 @@
+package helper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testLinodeCLIConfig = `[DEFAULT]
+default-user = alice
+
+[alice]
+token = alice-token
+region = us-ord
+
+[bob]
+token = bob-token
+api_host = api.staging.example.com
+api_version = v4beta
+
+[default]
+token = default-token
+api_url = http://localhost:8080
+
+[carol]
+region = us-east
+
+[dave]
+api_url = https://api.dev.example.com
+`
+
+// isolateCredentials clears the credential sources of the environment and
+// returns a temporary directory to write credential files to.
+func isolateCredentials(t *testing.T) string {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(TokenEnvVar, "")
+	t.Setenv(URLEnvVar, "")
+	t.Setenv(APIVersionEnvVar, "")
+	return dir
+}
+
+func writeFile(t *testing.T, path, content string) string {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLinodeCommonPrepare_Credentials(t *testing.T) {
+	dir := isolateCredentials(t)
+	configPath := writeFile(t, filepath.Join(dir, ".config", "linode-cli"), testLinodeCLIConfig)
+	tokenFile := writeFile(t, filepath.Join(dir, "token"), "file-token\n")
+
+	tests := []struct {
+		name               string
+		config             LinodeCommon
+		env                map[string]string
+		expectedToken      string
+		expectedAPIURL     string
+		expectedAPIVersion string
+	}{
+		{
+			name:          "linode_token",
+			config:        LinodeCommon{PersonalAccessToken: "token", TokenFile: tokenFile},
+			env:           map[string]string{TokenEnvVar: "env-token"},
+			expectedToken: "token",
+		},
+		{
+			name:          "linode_token_file",
+			config:        LinodeCommon{TokenFile: tokenFile},
+			env:           map[string]string{TokenEnvVar: "env-token"},
+			expectedToken: "file-token",
+		},
+		{
+			name:          "environment",
+			env:           map[string]string{TokenEnvVar: "env-token"},
+			expectedToken: "env-token",
+		},
+		{
+			name:          "default user of the default config file",
+			expectedToken: "alice-token",
+		},
+		{
+			name:               "profile",
+			config:             LinodeCommon{Profile: "bob"},
+			expectedToken:      "bob-token",
+			expectedAPIURL:     "https://api.staging.example.com",
+			expectedAPIVersion: "v4beta",
+		},
+		{
+			name:           "profile api_url",
+			config:         LinodeCommon{ConfigPath: configPath, Profile: "default"},
+			expectedToken:  "default-token",
+			expectedAPIURL: "http://localhost:8080",
+		},
+		{
+			name:           "environment over default profile",
+			env:            map[string]string{TokenEnvVar: "env-token", URLEnvVar: "https://proxy.example.com"},
+			expectedToken:  "env-token",
+			expectedAPIURL: "https://proxy.example.com",
+		},
+		{
+			name:           "explicit profile over environment",
+			config:         LinodeCommon{Profile: "default"},
+			env:            map[string]string{TokenEnvVar: "env-token"},
+			expectedToken:  "default-token",
+			expectedAPIURL: "http://localhost:8080",
+		},
+		{
+			name:               "profile token with LINODE_URL",
+			config:             LinodeCommon{Profile: "bob"},
+			env:                map[string]string{URLEnvVar: "https://attacker.example.com", APIVersionEnvVar: "v4"},
+			expectedToken:      "bob-token",
+			expectedAPIURL:     "https://api.staging.example.com",
+			expectedAPIVersion: "v4beta",
+		},
+		{
+			name:          "profile token without api_url with LINODE_URL",
+			config:        LinodeCommon{Profile: "alice"},
+			env:           map[string]string{URLEnvVar: "https://attacker.example.com"},
+			expectedToken: "alice-token",
+		},
+		{
+			name:               "api_url with explicit profile",
+			config:             LinodeCommon{Profile: "bob", APIURL: "https://proxy.example.com"},
+			env:                map[string]string{URLEnvVar: "https://attacker.example.com"},
+			expectedToken:      "bob-token",
+			expectedAPIURL:     "https://proxy.example.com",
+			expectedAPIVersion: "v4beta",
+		},
+		{
+			name:          "environment with explicit profile without token",
+			config:        LinodeCommon{ConfigPath: configPath, Profile: "carol"},
+			env:           map[string]string{TokenEnvVar: "env-token"},
+			expectedToken: "env-token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			c := tt.config
+			if errs := c.Prepare(); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			if c.PersonalAccessToken != tt.expectedToken {
+				t.Errorf("got token %q, expected %q", c.PersonalAccessToken, tt.expectedToken)
+			}
+			if c.APIURL != tt.expectedAPIURL {
+				t.Errorf("got api_url %q, expected %q", c.APIURL, tt.expectedAPIURL)
+			}
+			if c.APIVersion != tt.expectedAPIVersion {
+				t.Errorf("got api_version %q, expected %q", c.APIVersion, tt.expectedAPIVersion)
+			}
+		})
+	}
+}
+
+func TestLinodeCommonPrepare_CredentialErrors(t *testing.T) {
+	dir := isolateCredentials(t)
+	configPath := writeFile(t, filepath.Join(dir, "linode-cli"), testLinodeCLIConfig)
+	emptyFile := writeFile(t, filepath.Join(dir, "empty"), "\n")
+
+	for name, c := range map[string]LinodeCommon{
+		"missing token file":  {TokenFile: filepath.Join(dir, "missing")},
+		"empty token file":    {TokenFile: emptyFile},
+		"missing config file": {ConfigPath: filepath.Join(dir, "missing")},
+		"missing profile":     {ConfigPath: configPath, Profile: "erin"},
+		"no config file":      {Profile: "alice"},
+	} {
+		if errs := c.Prepare(); len(errs) == 0 {
+			t.Errorf("%s: should have error", name)
+		}
+	}
+
+	// The environment token is never sent to the API of a profile without one
+	t.Setenv(TokenEnvVar, "env-token")
+	c := LinodeCommon{ConfigPath: configPath, Profile: "dave"}
+	if errs := c.Prepare(); len(errs) == 0 {
+		t.Error("env token with a profile api_url: should have error")
+	}
+	t.Setenv(TokenEnvVar, "")
+
+	// Without any source, the token is left for the caller to require
+	c = LinodeCommon{}
+	if errs := c.Prepare(); len(errs) > 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if c.PersonalAccessToken != "" {
+		t.Errorf("got token %q", c.PersonalAccessToken)
+	}
+}
@@ -0,0 +1,281 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+)
+
+// AddImageToShareGroups adds the image to each of the given Image Share Groups.
+func AddImageToShareGroups(
+	ctx context.Context,
+	client *linodego.Client,
+	ui packersdk.Ui,
+	imageID string,
+	shareGroupIDs []int,
+) error {
+	for _, shareGroupID := range shareGroupIDs {
+		ui.Say(fmt.Sprintf(
+			"Adding image %s to image share group %d...",
+			imageID,
+			shareGroupID,
+		))
+
+		_, err := client.ImageShareGroupAddImages(
+			ctx,
+			shareGroupID,
+			linodego.ImageShareGroupAddImagesOptions{
+				Images: []linodego.ImageShareGroupImage{
+					{
+						ID: imageID,
+					},
+				},
+			},
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to add image %s to image share group %d: %w",
+				imageID,
+				shareGroupID,
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// RemoveImageFromShareGroups removes the image from each of the given Image
+// Share Groups it is shared in.
+func RemoveImageFromShareGroups(
+	ctx context.Context,
+	client *linodego.Client,
+	ui packersdk.Ui,
+	imageID string,
+	shareGroupIDs []int,
+) error {
+	for _, shareGroupID := range shareGroupIDs {
+		entries, err := client.ImageShareGroupListImageShareEntries(ctx, shareGroupID, nil)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to list the images of image share group %d: %w",
+				shareGroupID,
+				err,
+			)
+		}
+
+		for _, entry := range entries {
+			sharedBy := entry.ImageSharing.SharedBy
+			if sharedBy == nil || sharedBy.SourceImageID == nil || *sharedBy.SourceImageID != imageID {
+				continue
+			}
+
+			ui.Say(fmt.Sprintf(
+				"Removing image %s from image share group %d...",
+				imageID,
+				shareGroupID,
+			))
+
+			if err := client.ImageShareGroupRemoveImage(ctx, shareGroupID, entry.ID); err != nil {
+				return fmt.Errorf(
+					"failed to remove image %s from image share group %d: %w",
+					imageID,
+					shareGroupID,
+					err,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Policies of image_replication_failure.
+const (
+	ReplicationFailureFail   = "fail"
+	ReplicationFailureWarn   = "warn"
+	ReplicationFailureIgnore = "ignore"
+)
+
+// ValidateReplicationFailure returns an error if the policy is not one of
+// the image_replication_failure policies.
+func ValidateReplicationFailure(policy string) error {
+	switch policy {
+	case ReplicationFailureFail, ReplicationFailureWarn, ReplicationFailureIgnore:
+		return nil
+	default:
+		return errors.New("image_replication_failure must be one of fail, warn or ignore")
+	}
+}
+
+// HandleReplicationFailure applies the image_replication_failure policy to
+// the error returned by ReplicateImage. A *ReplicationError is reported as
+// an error with warn, only logged with ignore, and nil is returned for both.
+// Any other error is returned as is.
+func HandleReplicationFailure(ui packersdk.Ui, imageID string, err error, policy string) error {
+	var replicationErr *ReplicationError
+	if !errors.As(err, &replicationErr) || policy == ReplicationFailureFail {
+		return err
+	}
+
+	if policy == ReplicationFailureWarn {
+		ui.Error(fmt.Sprintf("Warning: image %s: %s", imageID, err))
+	} else {
+		log.Printf("[WARN] Ignoring the replication failure of image %s: %s", imageID, err)
+	}
+	return nil
+}
+
+// ReplicationError is returned by ReplicateImage when the image could not
+// be replicated to some of the regions.
+type ReplicationError struct {
+	// Errors maps each region the image is not available in to the reason.
+	Errors map[string]error
+}
+
+// Regions returns the sorted regions the image could not be replicated to.
+func (e *ReplicationError) Regions() []string {
+	regions := make([]string, 0, len(e.Errors))
+	for region := range e.Errors {
+		regions = append(regions, region)
+	}
+	slices.Sort(regions)
+	return regions
+}
+
+func (e *ReplicationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, region := range e.Regions() {
+		messages = append(messages, fmt.Sprintf("%s: %s", region, e.Errors[region]))
+	}
+	return "failed to replicate the image to " + strings.Join(messages, "; ")
+}
+
+// ReplicationRegions returns the regions to replicate an image to. The
+// region the image was created in is always included, as the API removes the
+// image from the regions left out.
+func ReplicationRegions(imageRegions []string, region string) []string {
+	if region == "" || slices.Contains(imageRegions, region) {
+		return imageRegions
+	}
+	return append([]string{region}, imageRegions...)
+}
+
+// ImageRegions returns the regions the image is available in, and the
+// requested regions it is not.
+func ImageRegions(image *linodego.Image, requested []string) (available, failed []string) {
+	for _, r := range image.Regions {
+		if r.Status == linodego.ImageRegionStatusAvailable {
+			available = append(available, r.Region)
+		}
+	}
+
+	for _, r := range requested {
+		if !slices.Contains(available, r) && !slices.Contains(failed, r) {
+			failed = append(failed, r)
+		}
+	}
+	return available, failed
+}
+
+// ReplicateImage replicates the image to the given regions and waits, in
+// parallel and for at most timeout if it is not zero, for every replica to
+// become available. Duplicate regions and regions the image is already
+// available in, such as the region it was created in, are only requested
+// once and not waited for.
+//
+// When some replicas do not become available, the latest state of the image
+// is returned along with a *ReplicationError.
+func ReplicateImage(
+	ctx context.Context,
+	client *linodego.Client,
+	ui packersdk.Ui,
+	imageID string,
+	regions []string,
+	timeout time.Duration,
+) (*linodego.Image, error) {
+	var unique []string
+	for _, r := range regions {
+		if !slices.Contains(unique, r) {
+			unique = append(unique, r)
+		}
+	}
+
+	image, err := client.ReplicateImage(ctx, imageID, linodego.ImageReplicateOptions{
+		Regions: unique,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	available := make(map[string]bool, len(image.Regions))
+	for _, r := range image.Regions {
+		available[r.Region] = r.Status == linodego.ImageRegionStatusAvailable
+	}
+
+	var pending []string
+	for _, r := range unique {
+		if !available[r] {
+			pending = append(pending, r)
+		}
+	}
+	if len(pending) == 0 {
+		return image, nil
+	}
+
+	ui.Say(fmt.Sprintf("Replicating image %s to %s...", imageID, strings.Join(pending, ", ")))
+
+	waitCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		done   int
+		failed = make(map[string]error)
+	)
+
+	for _, r := range pending {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := client.WaitForImageRegionStatus(waitCtx, imageID, r, linodego.ImageRegionStatusAvailable)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				failed[r] = err
+				ui.Say(fmt.Sprintf("Image %s could not be replicated to %s: %s", imageID, r, err))
+				return
+			}
+
+			done++
+			ui.Say(fmt.Sprintf("Image %s is available in %s (%d/%d)", imageID, r, done, len(pending)))
+		}()
+	}
+	wg.Wait()
+
+	image, err = client.GetImage(ctx, imageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	if len(failed) > 0 {
+		return image, &ReplicationError{Errors: failed}
+	}
+	return image, nil
+}
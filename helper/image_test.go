@@ -0,0 +1,142 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+)
+
+func TestReplicateImage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// The source region is available, us-ord replicates in time and
+		// eu-west is stuck
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`{"id": "private/1", "regions": [
+				{"region": "us-east", "status": "available"},
+				{"region": "us-ord", "status": "pending replication"},
+				{"region": "eu-west", "status": "pending replication"}
+			]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "private/1", "regions": [
+			{"region": "us-east", "status": "available"},
+			{"region": "us-ord", "status": "available"},
+			{"region": "eu-west", "status": "replicating"}
+		]}`))
+	}))
+	defer server.Close()
+
+	c := LinodeCommon{PersonalAccessToken: "secret", APIURL: server.URL}
+	client, err := c.NewClient()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	client.SetPollDelay(10 * time.Millisecond)
+
+	image, err := ReplicateImage(
+		context.Background(), client, packersdk.TestUi(t), "private/1",
+		[]string{"us-east", "us-ord", "eu-west", "us-ord"}, 200*time.Millisecond)
+
+	var replicationErr *ReplicationError
+	if !errors.As(err, &replicationErr) {
+		t.Fatalf("got error %v, expected a replication error", err)
+	}
+	if regions := replicationErr.Regions(); !reflect.DeepEqual(regions, []string{"eu-west"}) {
+		t.Errorf("got failed regions %v, expected [eu-west]", regions)
+	}
+	if image == nil || len(image.Regions) != 3 {
+		t.Fatalf("got image %#v, expected the latest state of the image", image)
+	}
+
+	// Nothing is awaited when the image is already available everywhere
+	image, err = ReplicateImage(
+		context.Background(), client, packersdk.TestUi(t), "private/1", []string{"us-east"}, time.Nanosecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if image.ID != "private/1" {
+		t.Errorf("got image %q", image.ID)
+	}
+}
+
+func TestRemoveImageFromShareGroups(t *testing.T) {
+	var removed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodDelete {
+			removed = append(removed, r.URL.Path)
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"page": 1, "pages": 1, "results": 2, "data": [
+			{"id": "shared/1", "image_sharing": {"shared_by": {"source_image_id": "private/1"}}},
+			{"id": "shared/2", "image_sharing": {"shared_by": {"source_image_id": "private/2"}}}
+		]}`))
+	}))
+	defer server.Close()
+
+	c := LinodeCommon{PersonalAccessToken: "secret", APIURL: server.URL}
+	client, err := c.NewClient()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := RemoveImageFromShareGroups(
+		context.Background(), client, packersdk.TestUi(t), "private/1", []int{10, 20}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{
+		"/v4/images/sharegroups/10/images/shared/1",
+		"/v4/images/sharegroups/20/images/shared/1",
+	}
+	if !reflect.DeepEqual(removed, expected) {
+		t.Errorf("got removals %v, expected %v", removed, expected)
+	}
+}
+
+func TestReplicationRegions(t *testing.T) {
+	tests := []struct {
+		imageRegions []string
+		region       string
+		expected     []string
+	}{
+		{[]string{"us-ord", "eu-west"}, "us-east", []string{"us-east", "us-ord", "eu-west"}},
+		{[]string{"us-ord", "eu-west"}, "us-ord", []string{"us-ord", "eu-west"}},
+		{[]string{"us-ord"}, "", []string{"us-ord"}},
+	}
+
+	for _, tt := range tests {
+		if got := ReplicationRegions(tt.imageRegions, tt.region); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%v in %s: got %v, expected %v", tt.imageRegions, tt.region, got, tt.expected)
+		}
+	}
+}
+
+func TestImageRegions(t *testing.T) {
+	image := &linodego.Image{
+		Regions: []linodego.ImageRegion{
+			{Region: "us-east", Status: linodego.ImageRegionStatusAvailable},
+			{Region: "us-ord", Status: linodego.ImageRegionStatusAvailable},
+			{Region: "eu-west", Status: linodego.ImageRegionStatusReplicating},
+		},
+	}
+
+	available, failed := ImageRegions(image, []string{"us-ord", "eu-west", "ap-south", "eu-west"})
+	if expected := []string{"us-east", "us-ord"}; !reflect.DeepEqual(available, expected) {
+		t.Errorf("got available regions %v, expected %v", available, expected)
+	}
+	if expected := []string{"eu-west", "ap-south"}; !reflect.DeepEqual(failed, expected) {
+		t.Errorf("got failed regions %v, expected %v", failed, expected)
+	}
+}
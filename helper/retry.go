@@ -0,0 +1,231 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/linode/linodego"
+)
+
+const (
+	// DefaultAPIMaxRetries is the default number of times a failed API
+	// request is retried.
+	DefaultAPIMaxRetries = 5
+
+	// DefaultAPIRetryMaxWait is the default maximum time spent waiting to
+	// retry a failed API request.
+	DefaultAPIRetryMaxWait = 2 * time.Minute
+
+	retryInitialBackoff = time.Second
+	retryMaxBackoff     = 30 * time.Second
+
+	// linodeBusyMessage is the error of the requests rejected because the
+	// Linode is busy with another operation.
+	linodeBusyMessage = "Linode busy."
+
+	// maxInspectedBodySize bounds how much of an error response is read to
+	// look for linodeBusyMessage.
+	maxInspectedBodySize = 64 * 1024
+)
+
+// nonRetryableStatuses are the statuses of the API errors that
+// RetryWithBackoff does not retry.
+var nonRetryableStatuses = []int{
+	http.StatusUnauthorized,
+	http.StatusForbidden,
+	http.StatusNotFound,
+	http.StatusMethodNotAllowed,
+}
+
+// RetryPolicy configures the retries of failed API requests.
+type RetryPolicy struct {
+	// MaxRetries is the number of times a failed request is retried.
+	MaxRetries int
+
+	// MaxWait is the maximum time elapsed since the first attempt of a
+	// request, including the time spent in the attempts, after which it is
+	// no longer retried.
+	MaxWait time.Duration
+}
+
+// retryTransport retries the API requests that failed because of rate
+// limiting or a transient error, with exponential backoff. The Retry-After
+// header of the response is respected when present.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+
+	// sleep waits for the given duration, or until the request is cancelled.
+	sleep func(req *http.Request, d time.Duration) error
+}
+
+func newRetryTransport(base http.RoundTripper, policy RetryPolicy) *retryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &retryTransport{
+		base:   base,
+		policy: policy,
+		sleep: func(req *http.Request, d time.Duration) error {
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+
+			select {
+			case <-req.Context().Done():
+				return req.Context().Err()
+			case <-timer.C:
+				return nil
+			}
+		},
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Requests with a body can only be retried if the body can be replayed
+	rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	start := time.Now()
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(req)
+
+		if !rewindable || attempt >= t.policy.MaxRetries || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		wait := backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				wait = retryAfter
+			}
+		}
+
+		if time.Since(start)+wait > t.policy.MaxWait {
+			return resp, err
+		}
+
+		if resp != nil {
+			log.Printf("[INFO] Linode API request %s %s failed with status %d, retrying in %s",
+				req.Method, req.URL.Path, resp.StatusCode, wait)
+
+			// Drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		} else {
+			log.Printf("[INFO] Linode API request %s %s failed: %s, retrying in %s",
+				req.Method, req.URL.Path, err, wait)
+		}
+
+		if err := t.sleep(req, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// shouldRetry returns whether the attempt failed in a way that is worth
+// retrying. Requests that may have been processed, such as those that failed
+// with a server error, are only retried when they are idempotent.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	idempotent := slices.Contains([]string{
+		http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete,
+	}, req.Method)
+
+	if err != nil {
+		return idempotent && req.Context().Err() == nil
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		// The API is not retried during maintenance events
+		return resp.Header.Get("X-Maintenance-Mode") == ""
+	case http.StatusBadRequest:
+		return isLinodeBusy(resp)
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	default:
+		return false
+	}
+}
+
+// isLinodeBusy returns whether the response is the error of a request
+// rejected because the Linode is busy. The body of the response is left
+// readable.
+func isLinodeBusy(resp *http.Response) bool {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxInspectedBodySize))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+
+	return err == nil && strings.Contains(string(body), linodeBusyMessage)
+}
+
+// RetryWithBackoff calls fn until it succeeds, waiting with exponential
+// backoff between the attempts. It gives up once the context is done, or as
+// soon as fn returns an API error that retrying cannot fix, such as an
+// authentication or permission error.
+func RetryWithBackoff(ctx context.Context, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if linodego.ErrHasStatus(err, nonRetryableStatuses...) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+		case <-time.After(backoff(attempt)):
+		}
+	}
+}
+
+// backoff returns the exponential delay before the given retry.
+func backoff(attempt int) time.Duration {
+	if attempt >= 5 {
+		return retryMaxBackoff
+	}
+	return min(retryInitialBackoff<<attempt, retryMaxBackoff)
+}
+
+// parseRetryAfter parses a Retry-After header, given either in seconds or
+// as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+
+	return 0, false
+}
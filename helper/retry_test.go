@@ -0,0 +1,294 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego"
+)
+
+// newTestRetryTransport returns a retry transport that records its waits
+// instead of sleeping.
+func newTestRetryTransport(policy RetryPolicy, waits *[]time.Duration) *retryTransport {
+	t := newRetryTransport(nil, policy)
+	t.sleep = func(_ *http.Request, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+	return t
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		statuses      []int
+		headers       http.Header
+		body          string
+		policy        RetryPolicy
+		expectedCalls int32
+		expectedWaits []time.Duration
+		expectedCode  int
+	}{
+		{
+			name:          "success",
+			method:        http.MethodGet,
+			statuses:      []int{200},
+			policy:        RetryPolicy{MaxRetries: 3, MaxWait: time.Minute},
+			expectedCalls: 1,
+			expectedCode:  200,
+		},
+		{
+			name:          "exponential backoff",
+			method:        http.MethodGet,
+			statuses:      []int{502, 500, 504, 200},
+			policy:        RetryPolicy{MaxRetries: 3, MaxWait: time.Minute},
+			expectedCalls: 4,
+			expectedWaits: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+			expectedCode:  200,
+		},
+		{
+			name:          "retry after",
+			method:        http.MethodPost,
+			statuses:      []int{429, 200},
+			headers:       http.Header{"Retry-After": []string{"7"}},
+			policy:        RetryPolicy{MaxRetries: 3, MaxWait: time.Minute},
+			expectedCalls: 2,
+			expectedWaits: []time.Duration{7 * time.Second},
+			expectedCode:  200,
+		},
+		{
+			name:          "max retries",
+			method:        http.MethodGet,
+			statuses:      []int{503, 503, 503},
+			policy:        RetryPolicy{MaxRetries: 2, MaxWait: time.Minute},
+			expectedCalls: 3,
+			expectedWaits: []time.Duration{time.Second, 2 * time.Second},
+			expectedCode:  503,
+		},
+		{
+			name:          "max wait",
+			method:        http.MethodGet,
+			statuses:      []int{429, 429},
+			headers:       http.Header{"Retry-After": []string{"90"}},
+			policy:        RetryPolicy{MaxRetries: 3, MaxWait: time.Minute},
+			expectedCalls: 1,
+			expectedCode:  429,
+		},
+		{
+			name:          "server errors of creations",
+			method:        http.MethodPost,
+			statuses:      []int{500, 200},
+			policy:        RetryPolicy{MaxRetries: 3, MaxWait: time.Minute},
+			expectedCalls: 1,
+			expectedCode:  500,
+		},
+		{
+			name:          "maintenance",
+			method:        http.MethodGet,
+			statuses:      []int{503, 200},
+			headers:       http.Header{"X-Maintenance-Mode": []string{"true"}},
+			policy:        RetryPolicy{MaxRetries: 3, MaxWait: time.Minute},
+			expectedCalls: 1,
+			expectedCode:  503,
+		},
+		{
+			name:          "linode busy",
+			method:        http.MethodPost,
+			statuses:      []int{400, 200},
+			body:          `{"errors": [{"reason": "Linode busy."}]}`,
+			policy:        RetryPolicy{MaxRetries: 3, MaxWait: time.Minute},
+			expectedCalls: 2,
+			expectedWaits: []time.Duration{time.Second},
+			expectedCode:  200,
+		},
+		{
+			name:          "invalid request",
+			method:        http.MethodPost,
+			statuses:      []int{400, 200},
+			body:          `{"errors": [{"reason": "Label must be unique"}]}`,
+			policy:        RetryPolicy{MaxRetries: 3, MaxWait: time.Minute},
+			expectedCalls: 1,
+			expectedCode:  400,
+		},
+		{
+			name:          "retries disabled",
+			method:        http.MethodGet,
+			statuses:      []int{429, 200},
+			policy:        RetryPolicy{MaxRetries: 0, MaxWait: time.Minute},
+			expectedCalls: 1,
+			expectedCode:  429,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if r.Method == http.MethodPost && string(body) != `{"label":"test"}` {
+					t.Errorf("attempt %d: got body %q", calls.Load()+1, body)
+				}
+
+				status := tt.statuses[calls.Add(1)-1]
+				if status != http.StatusOK {
+					for k, v := range tt.headers {
+						w.Header()[k] = v
+					}
+				}
+				w.WriteHeader(status)
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			var waits []time.Duration
+			client := &http.Client{Transport: newTestRetryTransport(tt.policy, &waits)}
+
+			var body io.Reader
+			if tt.method == http.MethodPost {
+				body = strings.NewReader(`{"label":"test"}`)
+			}
+			req, err := http.NewRequest(tt.method, server.URL, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedCode {
+				t.Errorf("got status %d, expected %d", resp.StatusCode, tt.expectedCode)
+			}
+			if respBody, _ := io.ReadAll(resp.Body); string(respBody) != tt.body {
+				t.Errorf("got body %q, expected %q", respBody, tt.body)
+			}
+			if got := calls.Load(); got != tt.expectedCalls {
+				t.Errorf("got %d calls, expected %d", got, tt.expectedCalls)
+			}
+			if len(waits) != len(tt.expectedWaits) {
+				t.Fatalf("got waits %v, expected %v", waits, tt.expectedWaits)
+			}
+			for i := range waits {
+				if waits[i] != tt.expectedWaits[i] {
+					t.Errorf("got waits %v, expected %v", waits, tt.expectedWaits)
+					break
+				}
+			}
+		})
+	}
+}
+
+// slowTransport is a base transport whose requests take the given time.
+type slowTransport struct {
+	delay time.Duration
+}
+
+func (t slowTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	time.Sleep(t.delay)
+	return &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    req,
+	}, nil
+}
+
+func TestRetryTransport_SlowRequests(t *testing.T) {
+	// The time spent in the attempts counts towards the maximum wait, so the
+	// request is not retried although the first backoff alone would fit
+	var waits []time.Duration
+	transport := newTestRetryTransport(RetryPolicy{MaxRetries: 3, MaxWait: time.Second + 50*time.Millisecond}, &waits)
+	transport.base = slowTransport{delay: 100 * time.Millisecond}
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.linode.com/v4/profile", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("got status %d, expected %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+	if len(waits) != 0 {
+		t.Errorf("got waits %v, expected no retry", waits)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{value: "", ok: false},
+		{value: "30", expected: 30 * time.Second, ok: true},
+		{value: "-1", ok: false},
+		{value: "Mon, 01 Jan 2024 12:00:45 GMT", expected: 45 * time.Second, ok: true},
+		{value: "Mon, 01 Jan 2024 11:00:00 GMT", expected: 0, ok: true},
+		{value: "soon", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if ok != tt.ok || got != tt.expected {
+			t.Errorf("%q: got (%s, %t), expected (%s, %t)", tt.value, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestRetryWithBackoff(t *testing.T) {
+	var calls int
+	if err := RetryWithBackoff(context.Background(), func() error {
+		calls++
+		return nil
+	}); err != nil || calls != 1 {
+		t.Errorf("got (%v, %d calls), expected success after 1 call", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls = 0
+	err := RetryWithBackoff(ctx, func() error {
+		calls++
+		return errors.New("busy")
+	})
+	if err == nil || !strings.Contains(err.Error(), "busy") {
+		t.Errorf("got error %v, expected the last error", err)
+	}
+	if calls != 1 {
+		t.Errorf("got %d calls, expected 1", calls)
+	}
+
+	// API errors that retrying cannot fix are returned right away
+	for _, code := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound} {
+		calls = 0
+		err = RetryWithBackoff(context.Background(), func() error {
+			calls++
+			return &linodego.Error{Code: code, Message: http.StatusText(code)}
+		})
+		if !linodego.ErrHasStatus(err, code) {
+			t.Errorf("%d: got error %v, expected the API error", code, err)
+		}
+		if calls != 1 {
+			t.Errorf("%d: got %d calls, expected 1", code, calls)
+		}
+	}
+}
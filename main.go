@@ -6,6 +6,8 @@ import (
 
 	"github.com/linode/packer-plugin-linode/builder/linode"
 	"github.com/linode/packer-plugin-linode/datasource/image"
+	imageretention "github.com/linode/packer-plugin-linode/post-processor/image-retention"
+	linodeimport "github.com/linode/packer-plugin-linode/post-processor/import"
 	"github.com/linode/packer-plugin-linode/version"
 
 	"github.com/hashicorp/packer-plugin-sdk/plugin"
@@ -15,6 +17,8 @@ func main() {
 	pps := plugin.NewSet()
 	pps.RegisterDatasource("image", new(image.Datasource))
 	pps.RegisterBuilder(plugin.DEFAULT_NAME, new(linode.Builder))
+	pps.RegisterPostProcessor("import", new(linodeimport.PostProcessor))
+	pps.RegisterPostProcessor("image-retention", new(imageretention.PostProcessor))
 	pps.SetVersion(version.PluginVersion)
 	err := pps.Run()
 	if err != nil {
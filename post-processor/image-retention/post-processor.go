@@ -0,0 +1,202 @@
+//go:generate packer-sdc struct-markdown
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config
+
+// The imageretention package contains a packersdk.PostProcessor
+// implementation that prunes old private Linode images.
+package imageretention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-sdk/common"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+	"github.com/linode/linodego"
+	"github.com/linode/packer-plugin-linode/datasource/image"
+	"github.com/linode/packer-plugin-linode/helper"
+)
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+	helper.LinodeCommon `mapstructure:",squash"`
+	ctx                 interpolate.Context
+
+	// A regular expression the labels of the images to prune must match.
+	// At least one of `label_regex` and `tags` is required.
+	LabelRegex string `mapstructure:"label_regex" required:"false"`
+
+	// Tags the images to prune must all have. At least one of `label_regex`
+	// and `tags` is required.
+	Tags []string `mapstructure:"tags" required:"false"`
+
+	// The number of newest matching images to keep. At least one of
+	// `keep_count` and `keep_newer_than` is required.
+	KeepCount int `mapstructure:"keep_count" required:"false"`
+
+	// Matching images created within this duration, such as "720h", are kept
+	// regardless of `keep_count`. At least one of `keep_count` and
+	// `keep_newer_than` is required.
+	KeepNewerThan time.Duration `mapstructure:"keep_newer_than" required:"false"`
+
+	// Only list the images that would be deleted, without deleting them.
+	DryRun bool `mapstructure:"dry_run" required:"false"`
+}
+
+type PostProcessor struct {
+	config Config
+}
+
+func (p *PostProcessor) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+
+func (p *PostProcessor) Configure(raws ...any) error {
+	if err := config.Decode(&p.config, &config.DecodeOpts{
+		PluginType:         "linode-image-retention",
+		Interpolate:        true,
+		InterpolateContext: &p.config.ctx,
+	}, raws...); err != nil {
+		return err
+	}
+
+	var errs *packersdk.MultiError
+
+	if p.config.APICAPath == "" {
+		p.config.APICAPath = os.Getenv("LINODE_CA")
+	}
+
+	errs = packersdk.MultiErrorAppend(errs, p.config.LinodeCommon.Prepare()...)
+
+	if p.config.PersonalAccessToken == "" {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("linode_token is required"))
+	}
+
+	// Pruning every private image of the account is never intended
+	if p.config.LabelRegex == "" && len(p.config.Tags) == 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("at least one of label_regex and tags is required"))
+	}
+
+	if p.config.LabelRegex != "" {
+		if _, err := regexp.Compile(p.config.LabelRegex); err != nil {
+			errs = packersdk.MultiErrorAppend(
+				errs, fmt.Errorf("invalid label_regex: %w", err))
+		}
+	}
+
+	if p.config.KeepCount < 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("keep_count must not be negative"))
+	}
+
+	if p.config.KeepNewerThan < 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("keep_newer_than must not be negative"))
+	}
+
+	if p.config.KeepCount == 0 && p.config.KeepNewerThan == 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("at least one of keep_count and keep_newer_than is required"))
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return errs
+	}
+
+	packersdk.LogSecretFilter.Set(p.config.PersonalAccessToken)
+	return nil
+}
+
+func (p *PostProcessor) PostProcess(
+	ctx context.Context, ui packersdk.Ui, source packersdk.Artifact,
+) (packersdk.Artifact, bool, bool, error) {
+	client, err := p.config.NewClient()
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	filter := linodego.Filter{}
+	filter.AddField(linodego.Eq, "is_public", false)
+	filterString, err := filter.MarshalJSON()
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	images, err := client.ListImages(ctx, linodego.NewListOptions(0, string(filterString)))
+	if err != nil {
+		return nil, false, false, fmt.Errorf("failed to list images: %w", err)
+	}
+
+	if p.config.LabelRegex != "" {
+		images = image.FilterImagesByLabelRegex(images, p.config.LabelRegex)
+	}
+	if len(p.config.Tags) > 0 {
+		images = image.FilterImagesByTags(images, p.config.Tags)
+	}
+
+	// The images of the incoming artifact are never deleted
+	protected := strings.Split(source.Id(), ",")
+
+	prune := imagesToPrune(images, protected, p.config.KeepCount, p.config.KeepNewerThan, time.Now())
+	if len(prune) == 0 {
+		ui.Say("No images to prune")
+	}
+
+	var errs []error
+	for _, img := range prune {
+		if p.config.DryRun {
+			ui.Say(fmt.Sprintf("Would delete image %s (%s), created %s",
+				img.ID, img.Label, img.Created.Format(time.RFC3339)))
+			continue
+		}
+
+		ui.Say(fmt.Sprintf("Deleting image %s (%s)...", img.ID, img.Label))
+		if err := client.DeleteImage(ctx, img.ID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete image %s: %w", img.ID, err))
+		}
+	}
+
+	// The incoming artifact is passed through and must not be destroyed
+	return source, true, true, errors.Join(errs...)
+}
+
+// imagesToPrune returns the images to delete, newest first. The keepCount
+// newest images, the images created after now minus keepNewerThan and the
+// protected images are kept, as are images that are not available or were
+// not created manually.
+func imagesToPrune(
+	images []linodego.Image,
+	protected []string,
+	keepCount int,
+	keepNewerThan time.Duration,
+	now time.Time,
+) []linodego.Image {
+	candidates := slices.DeleteFunc(slices.Clone(images), func(img linodego.Image) bool {
+		return img.IsPublic || img.Type != "manual" ||
+			img.Status != linodego.ImageStatusAvailable || img.Created == nil
+	})
+
+	slices.SortFunc(candidates, func(a, b linodego.Image) int {
+		return b.Created.Compare(*a.Created)
+	})
+
+	var prune []linodego.Image
+	for i, img := range candidates {
+		switch {
+		case i < keepCount:
+		case keepNewerThan > 0 && now.Sub(*img.Created) < keepNewerThan:
+		case slices.Contains(protected, img.ID):
+		default:
+			prune = append(prune, img)
+		}
+	}
+	return prune
+}
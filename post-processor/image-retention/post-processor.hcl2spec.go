@@ -0,0 +1,73 @@
+// Code generated by "packer-sdc mapstructure-to-hcl2"; DO NOT EDIT.
+
+package imageretention
+
+import (
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// FlatConfig is an auto-generated flat version of Config.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatConfig struct {
+	PackerBuildName     *string           `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
+	PackerBuilderType   *string           `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
+	PackerCoreVersion   *string           `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
+	PackerDebug         *bool             `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
+	PackerForce         *bool             `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
+	PackerOnError       *string           `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
+	PackerUserVars      map[string]string `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
+	PackerSensitiveVars []string          `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
+	PersonalAccessToken *string           `mapstructure:"linode_token" cty:"linode_token" hcl:"linode_token"`
+	TokenFile           *string           `mapstructure:"linode_token_file" cty:"linode_token_file" hcl:"linode_token_file"`
+	ConfigPath          *string           `mapstructure:"linode_config_path" cty:"linode_config_path" hcl:"linode_config_path"`
+	Profile             *string           `mapstructure:"linode_profile" cty:"linode_profile" hcl:"linode_profile"`
+	APICAPath           *string           `mapstructure:"api_ca_path" cty:"api_ca_path" hcl:"api_ca_path"`
+	APIURL              *string           `mapstructure:"api_url" cty:"api_url" hcl:"api_url"`
+	APIVersion          *string           `mapstructure:"api_version" cty:"api_version" hcl:"api_version"`
+	APIMaxRetries       *int              `mapstructure:"api_max_retries" cty:"api_max_retries" hcl:"api_max_retries"`
+	APIRetryMaxWait     *string           `mapstructure:"api_retry_max_wait" cty:"api_retry_max_wait" hcl:"api_retry_max_wait"`
+	LabelRegex          *string           `mapstructure:"label_regex" required:"false" cty:"label_regex" hcl:"label_regex"`
+	Tags                []string          `mapstructure:"tags" required:"false" cty:"tags" hcl:"tags"`
+	KeepCount           *int              `mapstructure:"keep_count" required:"false" cty:"keep_count" hcl:"keep_count"`
+	KeepNewerThan       *string           `mapstructure:"keep_newer_than" required:"false" cty:"keep_newer_than" hcl:"keep_newer_than"`
+	DryRun              *bool             `mapstructure:"dry_run" required:"false" cty:"dry_run" hcl:"dry_run"`
+}
+
+// FlatMapstructure returns a new FlatConfig.
+// FlatConfig is an auto-generated flat version of Config.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*Config) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatConfig)
+}
+
+// HCL2Spec returns the hcl spec of a Config.
+// This spec is used by HCL to read the fields of Config.
+// The decoded values from this spec will then be applied to a FlatConfig.
+func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"packer_build_name":          &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
+		"packer_builder_type":        &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
+		"packer_core_version":        &hcldec.AttrSpec{Name: "packer_core_version", Type: cty.String, Required: false},
+		"packer_debug":               &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
+		"packer_force":               &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
+		"packer_on_error":            &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
+		"packer_user_variables":      &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
+		"packer_sensitive_variables": &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
+		"linode_token":               &hcldec.AttrSpec{Name: "linode_token", Type: cty.String, Required: false},
+		"linode_token_file":          &hcldec.AttrSpec{Name: "linode_token_file", Type: cty.String, Required: false},
+		"linode_config_path":         &hcldec.AttrSpec{Name: "linode_config_path", Type: cty.String, Required: false},
+		"linode_profile":             &hcldec.AttrSpec{Name: "linode_profile", Type: cty.String, Required: false},
+		"api_ca_path":                &hcldec.AttrSpec{Name: "api_ca_path", Type: cty.String, Required: false},
+		"api_url":                    &hcldec.AttrSpec{Name: "api_url", Type: cty.String, Required: false},
+		"api_version":                &hcldec.AttrSpec{Name: "api_version", Type: cty.String, Required: false},
+		"api_max_retries":            &hcldec.AttrSpec{Name: "api_max_retries", Type: cty.Number, Required: false},
+		"api_retry_max_wait":         &hcldec.AttrSpec{Name: "api_retry_max_wait", Type: cty.String, Required: false},
+		"label_regex":                &hcldec.AttrSpec{Name: "label_regex", Type: cty.String, Required: false},
+		"tags":                       &hcldec.AttrSpec{Name: "tags", Type: cty.List(cty.String), Required: false},
+		"keep_count":                 &hcldec.AttrSpec{Name: "keep_count", Type: cty.Number, Required: false},
+		"keep_newer_than":            &hcldec.AttrSpec{Name: "keep_newer_than", Type: cty.String, Required: false},
+		"dry_run":                    &hcldec.AttrSpec{Name: "dry_run", Type: cty.Bool, Required: false},
+	}
+	return s
+}
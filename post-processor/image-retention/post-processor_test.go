@@ -0,0 +1,125 @@
+package imageretention
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+	"github.com/linode/packer-plugin-linode/helper"
+)
+
+func testConfig() map[string]any {
+	return map[string]any{
+		"linode_token": "bar",
+		"label_regex":  "^nightly-",
+		"keep_count":   3,
+	}
+}
+
+func TestPostProcessor_ImplementsPostProcessor(t *testing.T) {
+	var raw any = &PostProcessor{}
+	if _, ok := raw.(packersdk.PostProcessor); !ok {
+		t.Fatalf("PostProcessor should be a post-processor")
+	}
+}
+
+func TestPostProcessorConfigure(t *testing.T) {
+	t.Setenv(helper.TokenEnvVar, "")
+
+	var p PostProcessor
+	if err := p.Configure(testConfig()); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	tests := map[string]func(map[string]any){
+		"missing linode_token": func(c map[string]any) { delete(c, "linode_token") },
+		"missing matchers":     func(c map[string]any) { delete(c, "label_regex") },
+		"invalid label_regex":  func(c map[string]any) { c["label_regex"] = "[" },
+		"missing keep":         func(c map[string]any) { delete(c, "keep_count") },
+		"negative keep_count":  func(c map[string]any) { c["keep_count"] = -1 },
+		"negative keep_newer_than": func(c map[string]any) {
+			c["keep_newer_than"] = "-1h"
+		},
+	}
+
+	for name, modify := range tests {
+		config := testConfig()
+		modify(config)
+
+		p = PostProcessor{}
+		if err := p.Configure(config); err == nil {
+			t.Errorf("%s: should have error", name)
+		}
+	}
+}
+
+func TestImagesToPrune(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		created := now.AddDate(0, 0, -days)
+		return &created
+	}
+	manual := func(id string, days int) linodego.Image {
+		return linodego.Image{
+			ID: id, Type: "manual", Status: linodego.ImageStatusAvailable, Created: daysAgo(days),
+		}
+	}
+
+	images := []linodego.Image{
+		manual("private/5", 5),
+		manual("private/1", 1),
+		manual("private/10", 10),
+		manual("private/3", 3),
+		manual("private/20", 20),
+		{ID: "private/30", Type: "automatic", Status: linodego.ImageStatusAvailable, Created: daysAgo(30)},
+		{ID: "private/40", Type: "manual", Status: linodego.ImageStatusCreating, Created: daysAgo(40)},
+	}
+
+	ids := func(images []linodego.Image) []string {
+		var ids []string
+		for _, image := range images {
+			ids = append(ids, image.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name          string
+		protected     []string
+		keepCount     int
+		keepNewerThan time.Duration
+		expected      []string
+	}{
+		{
+			name:      "keep count",
+			keepCount: 2,
+			expected:  []string{"private/5", "private/10", "private/20"},
+		},
+		{
+			name:          "keep newer than",
+			keepNewerThan: 7 * 24 * time.Hour,
+			expected:      []string{"private/10", "private/20"},
+		},
+		{
+			name:          "keep count or newer than",
+			keepCount:     4,
+			keepNewerThan: 2 * 24 * time.Hour,
+			expected:      []string{"private/20"},
+		},
+		{
+			name:      "protected",
+			protected: []string{"private/10"},
+			keepCount: 1,
+			expected:  []string{"private/3", "private/5", "private/20"},
+		},
+	}
+
+	for _, tt := range tests {
+		got := ids(imagesToPrune(images, tt.protected, tt.keepCount, tt.keepNewerThan, now))
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: got %v, expected %v", tt.name, got, tt.expected)
+		}
+	}
+}
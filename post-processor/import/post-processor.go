@@ -0,0 +1,394 @@
+//go:generate packer-sdc struct-markdown
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config
+
+// The linodeimport package contains a packersdk.PostProcessor implementation
+// that uploads locally built raw disk images to Linode.
+package linodeimport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-sdk/common"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+	"github.com/linode/linodego"
+	"github.com/linode/packer-plugin-linode/builder/linode"
+	"github.com/linode/packer-plugin-linode/helper"
+)
+
+// gzipMagic is the header every gzip stream starts with.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+	helper.LinodeCommon `mapstructure:",squash"`
+	ctx                 interpolate.Context
+
+	// The id of the region to upload the image to. See
+	// [regions](https://api.linode.com/v4/regions) for more information on
+	// the available regions.
+	Region string `mapstructure:"region" required:"true"`
+
+	// The name of the resulting image that will appear
+	// in your account. Defaults to `packer-{{timestamp}}` (see [configuration
+	// templates](/packer/docs/templates/legacy_json_templates/engine) for more info).
+	ImageLabel string `mapstructure:"image_label" required:"false"`
+
+	// The description of the resulting image that will appear in your account. Defaults to "".
+	Description string `mapstructure:"image_description" required:"false"`
+
+	// Whether the uploaded image supports cloud-init.
+	CloudInit bool `mapstructure:"cloud_init" required:"false"`
+
+	// The regions where the outcome image will be replicated to. The image
+	// is always kept in `region` as well.
+	ImageRegions []string `mapstructure:"image_regions" required:"false"`
+
+	// The time to wait, as a duration string, for the image to be replicated
+	// to all of the `image_regions`. Replicas are awaited in parallel. The
+	// default image replication timeout is "30m".
+	ImageReplicationTimeout time.Duration `mapstructure:"image_replication_timeout" required:"false"`
+
+	// What to do when the image could not be replicated to some of the
+	// `image_regions` in time. `fail` (the default) fails the import and
+	// deletes the image, `warn` reports the failed regions and `ignore` only
+	// logs them.
+	ImageReplicationFailure string `mapstructure:"image_replication_failure" required:"false"`
+
+	// Image Share Group IDs to add the newly uploaded private image to
+	// once it becomes available.
+	ImageShareGroupIDs []int `mapstructure:"image_share_group_ids" required:"false"`
+
+	// The time to wait, as a duration string, for the uploaded image to be
+	// processed and become available before timing out. The default is "30m".
+	ImageCreateTimeout time.Duration `mapstructure:"image_create_timeout" required:"false"`
+}
+
+type PostProcessor struct {
+	config Config
+}
+
+func (p *PostProcessor) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+
+func (p *PostProcessor) Configure(raws ...any) error {
+	if err := config.Decode(&p.config, &config.DecodeOpts{
+		PluginType:         "linode-import",
+		Interpolate:        true,
+		InterpolateContext: &p.config.ctx,
+	}, raws...); err != nil {
+		return err
+	}
+
+	var errs *packersdk.MultiError
+
+	if p.config.APICAPath == "" {
+		p.config.APICAPath = os.Getenv("LINODE_CA")
+	}
+
+	errs = packersdk.MultiErrorAppend(errs, p.config.LinodeCommon.Prepare()...)
+
+	if p.config.ImageLabel == "" {
+		if def, err := interpolate.Render("packer-{{timestamp}}", nil); err == nil {
+			p.config.ImageLabel = def
+		} else {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("unable to render image name: %s", err))
+		}
+	}
+
+	if p.config.ImageCreateTimeout == 0 {
+		// Uploaded images are decompressed and scanned before they become
+		// available, which takes longer than imaging a disk.
+		p.config.ImageCreateTimeout = 30 * time.Minute
+	}
+
+	if p.config.ImageReplicationTimeout == 0 {
+		p.config.ImageReplicationTimeout = 30 * time.Minute
+	} else if p.config.ImageReplicationTimeout < 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("image_replication_timeout must not be negative"))
+	}
+
+	if p.config.ImageReplicationFailure == "" {
+		p.config.ImageReplicationFailure = helper.ReplicationFailureFail
+	}
+	if err := helper.ValidateReplicationFailure(p.config.ImageReplicationFailure); err != nil {
+		errs = packersdk.MultiErrorAppend(errs, err)
+	}
+
+	if p.config.PersonalAccessToken == "" {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("linode_token is required"))
+	}
+
+	if p.config.Region == "" {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("region is required"))
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return errs
+	}
+
+	packersdk.LogSecretFilter.Set(p.config.PersonalAccessToken)
+	return nil
+}
+
+func (p *PostProcessor) PostProcess(
+	ctx context.Context, ui packersdk.Ui, source packersdk.Artifact,
+) (packersdk.Artifact, bool, bool, error) {
+	if diskType, ok := source.State("diskType").(string); ok && diskType != "raw" {
+		return nil, false, false, fmt.Errorf(
+			"only raw disk images can be imported, got %q; set format = \"raw\" in the builder", diskType)
+	}
+
+	imagePath, err := findDiskImage(source.Files())
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	client, err := p.config.NewClient()
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	compressed, err := isGzip(imagePath)
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	uploadPath := imagePath
+	if !compressed {
+		ui.Say(fmt.Sprintf("Compressing %s...", imagePath))
+		uploadPath, err = compressImage(imagePath)
+		if err != nil {
+			return nil, false, false, fmt.Errorf("failed to compress disk image: %w", err)
+		}
+		defer os.Remove(uploadPath)
+	}
+
+	ui.Say("Creating image upload...")
+	image, uploadURL, err := client.CreateImageUpload(ctx, linodego.ImageCreateUploadOptions{
+		Region:      p.config.Region,
+		Label:       p.config.ImageLabel,
+		Description: p.config.Description,
+		CloudInit:   p.config.CloudInit,
+	})
+	if err != nil {
+		return nil, false, false, fmt.Errorf("failed to create image upload: %w", err)
+	}
+
+	ui.Say(fmt.Sprintf("Uploading %s to image %s...", uploadPath, image.ID))
+	if err := uploadImage(ctx, ui, uploadURL, uploadPath); err != nil {
+		return nil, false, false, deleteFailedImage(client, ui, image.ID, nil, fmt.Errorf("failed to upload image: %w", err))
+	}
+
+	ui.Say("Waiting for the image to become available...")
+	_, err = client.WaitForImageStatus(
+		ctx, image.ID, linodego.ImageStatusAvailable, int(p.config.ImageCreateTimeout.Seconds()))
+	if err != nil {
+		return nil, false, false, deleteFailedImage(
+			client, ui, image.ID, nil, fmt.Errorf("failed to wait for image %s: %w", image.ID, err))
+	}
+
+	if err := p.finishImage(ctx, client, ui, image.ID); err != nil {
+		return nil, false, false, deleteFailedImage(client, ui, image.ID, p.config.ImageShareGroupIDs, err)
+	}
+
+	image, err = client.GetImage(ctx, image.ID)
+	if err != nil {
+		return nil, false, false, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	artifact := linode.Artifact{
+		ImageLabel: image.Label,
+		ImageID:    image.ID,
+		Driver:     client,
+		StateData:  p.stateData(image, source),
+	}
+
+	return artifact, false, false, nil
+}
+
+// stateData returns the state of the artifact of the imported image, with
+// the same keys as the artifacts of the builder.
+func (p *PostProcessor) stateData(image *linodego.Image, source packersdk.Artifact) map[string]any {
+	available, failed := helper.ImageRegions(image, p.config.ImageRegions)
+	return map[string]any{
+		"generated_data":                   source.State("generated_data"),
+		"region":                           p.config.Region,
+		"image_regions":                    available,
+		"image_replication_failed_regions": failed,
+		"cloud_init":                       slices.Contains(image.Capabilities, "cloud-init"),
+		"image_size":                       image.Size,
+		"image_share_group_ids":            p.config.ImageShareGroupIDs,
+	}
+}
+
+// finishImage shares and replicates the uploaded image as configured, once
+// it is available.
+func (p *PostProcessor) finishImage(ctx context.Context, client *linodego.Client, ui packersdk.Ui, imageID string) error {
+	if len(p.config.ImageShareGroupIDs) > 0 {
+		if err := helper.AddImageToShareGroups(ctx, client, ui, imageID, p.config.ImageShareGroupIDs); err != nil {
+			return err
+		}
+	}
+
+	if len(p.config.ImageRegions) > 0 {
+		regions := helper.ReplicationRegions(p.config.ImageRegions, p.config.Region)
+		_, err := helper.ReplicateImage(ctx, client, ui, imageID, regions, p.config.ImageReplicationTimeout)
+		if err := helper.HandleReplicationFailure(ui, imageID, err, p.config.ImageReplicationFailure); err != nil {
+			return fmt.Errorf("failed to replicate the image: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// deleteFailedImage removes an image that could not be imported from the
+// given share groups and deletes it, so that no incomplete image is left in
+// the account, and returns the import error along with the cleanup errors,
+// if any. The cleanup is not bound to the context of the build, which may be
+// the reason of the failure.
+func deleteFailedImage(
+	client *linodego.Client,
+	ui packersdk.Ui,
+	imageID string,
+	shareGroupIDs []int,
+	err error,
+) error {
+	ctx := context.Background()
+	errs := []error{err}
+
+	if len(shareGroupIDs) > 0 {
+		if shareErr := helper.RemoveImageFromShareGroups(ctx, client, ui, imageID, shareGroupIDs); shareErr != nil {
+			errs = append(errs, shareErr)
+		}
+	}
+
+	ui.Say(fmt.Sprintf("Deleting image %s...", imageID))
+	if deleteErr := client.DeleteImage(ctx, imageID); deleteErr != nil {
+		errs = append(errs, fmt.Errorf("failed to delete image %s: %w", imageID, deleteErr))
+	}
+	return errors.Join(errs...)
+}
+
+// findDiskImage returns the disk image file among the artifact files.
+// A single file is always used; otherwise the first file that looks like
+// a raw or compressed disk image is picked.
+func findDiskImage(files []string) (string, error) {
+	if len(files) == 1 {
+		return files[0], nil
+	}
+
+	for _, f := range files {
+		name := strings.TrimSuffix(f, ".gz")
+		switch filepath.Ext(name) {
+		case ".img", ".raw":
+			return f, nil
+		}
+	}
+
+	return "", fmt.Errorf("no raw disk image found among the artifact files %v", files)
+}
+
+// isGzip reports whether the file at the given path is gzip-compressed.
+func isGzip(path string) (bool, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	header := make([]byte, len(gzipMagic))
+	if _, err := io.ReadFull(f, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return bytes.Equal(header, gzipMagic), nil
+}
+
+// compressImage gzips the file at the given path into a temporary file
+// and returns the path of the temporary file.
+func compressImage(path string) (string, error) {
+	src, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp("", "packer-linode-import-*.img.gz")
+	if err != nil {
+		return "", err
+	}
+
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst.Name())
+		return "", err
+	}
+
+	return dst.Name(), nil
+}
+
+// uploadImage streams the file at the given path to the upload URL.
+// linodego's UploadImageToURL buffers the whole body in memory to compute
+// its length, so the request is built here to stream large images and
+// report the progress in the UI.
+func uploadImage(ctx context.Context, ui packersdk.Ui, uploadURL, path string) error {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	body := ui.TrackProgress(filepath.Base(path), 0, info.Size(), f)
+	defer body.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+// Code generated by "packer-sdc mapstructure-to-hcl2"; DO NOT EDIT.
+
+package linodeimport
+
+import (
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// FlatConfig is an auto-generated flat version of Config.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatConfig struct {
+	PackerBuildName         *string           `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
+	PackerBuilderType       *string           `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
+	PackerCoreVersion       *string           `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
+	PackerDebug             *bool             `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
+	PackerForce             *bool             `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
+	PackerOnError           *string           `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
+	PackerUserVars          map[string]string `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
+	PackerSensitiveVars     []string          `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
+	PersonalAccessToken     *string           `mapstructure:"linode_token" cty:"linode_token" hcl:"linode_token"`
+	TokenFile               *string           `mapstructure:"linode_token_file" cty:"linode_token_file" hcl:"linode_token_file"`
+	ConfigPath              *string           `mapstructure:"linode_config_path" cty:"linode_config_path" hcl:"linode_config_path"`
+	Profile                 *string           `mapstructure:"linode_profile" cty:"linode_profile" hcl:"linode_profile"`
+	APICAPath               *string           `mapstructure:"api_ca_path" cty:"api_ca_path" hcl:"api_ca_path"`
+	APIURL                  *string           `mapstructure:"api_url" cty:"api_url" hcl:"api_url"`
+	APIVersion              *string           `mapstructure:"api_version" cty:"api_version" hcl:"api_version"`
+	APIMaxRetries           *int              `mapstructure:"api_max_retries" cty:"api_max_retries" hcl:"api_max_retries"`
+	APIRetryMaxWait         *string           `mapstructure:"api_retry_max_wait" cty:"api_retry_max_wait" hcl:"api_retry_max_wait"`
+	Region                  *string           `mapstructure:"region" required:"true" cty:"region" hcl:"region"`
+	ImageLabel              *string           `mapstructure:"image_label" required:"false" cty:"image_label" hcl:"image_label"`
+	Description             *string           `mapstructure:"image_description" required:"false" cty:"image_description" hcl:"image_description"`
+	CloudInit               *bool             `mapstructure:"cloud_init" required:"false" cty:"cloud_init" hcl:"cloud_init"`
+	ImageRegions            []string          `mapstructure:"image_regions" required:"false" cty:"image_regions" hcl:"image_regions"`
+	ImageReplicationTimeout *string           `mapstructure:"image_replication_timeout" required:"false" cty:"image_replication_timeout" hcl:"image_replication_timeout"`
+	ImageReplicationFailure *string           `mapstructure:"image_replication_failure" required:"false" cty:"image_replication_failure" hcl:"image_replication_failure"`
+	ImageShareGroupIDs      []int             `mapstructure:"image_share_group_ids" required:"false" cty:"image_share_group_ids" hcl:"image_share_group_ids"`
+	ImageCreateTimeout      *string           `mapstructure:"image_create_timeout" required:"false" cty:"image_create_timeout" hcl:"image_create_timeout"`
+}
+
+// FlatMapstructure returns a new FlatConfig.
+// FlatConfig is an auto-generated flat version of Config.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*Config) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatConfig)
+}
+
+// HCL2Spec returns the hcl spec of a Config.
+// This spec is used by HCL to read the fields of Config.
+// The decoded values from this spec will then be applied to a FlatConfig.
+func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"packer_build_name":          &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
+		"packer_builder_type":        &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
+		"packer_core_version":        &hcldec.AttrSpec{Name: "packer_core_version", Type: cty.String, Required: false},
+		"packer_debug":               &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
+		"packer_force":               &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
+		"packer_on_error":            &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
+		"packer_user_variables":      &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
+		"packer_sensitive_variables": &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
+		"linode_token":               &hcldec.AttrSpec{Name: "linode_token", Type: cty.String, Required: false},
+		"linode_token_file":          &hcldec.AttrSpec{Name: "linode_token_file", Type: cty.String, Required: false},
+		"linode_config_path":         &hcldec.AttrSpec{Name: "linode_config_path", Type: cty.String, Required: false},
+		"linode_profile":             &hcldec.AttrSpec{Name: "linode_profile", Type: cty.String, Required: false},
+		"api_ca_path":                &hcldec.AttrSpec{Name: "api_ca_path", Type: cty.String, Required: false},
+		"api_url":                    &hcldec.AttrSpec{Name: "api_url", Type: cty.String, Required: false},
+		"api_version":                &hcldec.AttrSpec{Name: "api_version", Type: cty.String, Required: false},
+		"api_max_retries":            &hcldec.AttrSpec{Name: "api_max_retries", Type: cty.Number, Required: false},
+		"api_retry_max_wait":         &hcldec.AttrSpec{Name: "api_retry_max_wait", Type: cty.String, Required: false},
+		"region":                     &hcldec.AttrSpec{Name: "region", Type: cty.String, Required: false},
+		"image_label":                &hcldec.AttrSpec{Name: "image_label", Type: cty.String, Required: false},
+		"image_description":          &hcldec.AttrSpec{Name: "image_description", Type: cty.String, Required: false},
+		"cloud_init":                 &hcldec.AttrSpec{Name: "cloud_init", Type: cty.Bool, Required: false},
+		"image_regions":              &hcldec.AttrSpec{Name: "image_regions", Type: cty.List(cty.String), Required: false},
+		"image_replication_timeout":  &hcldec.AttrSpec{Name: "image_replication_timeout", Type: cty.String, Required: false},
+		"image_replication_failure":  &hcldec.AttrSpec{Name: "image_replication_failure", Type: cty.String, Required: false},
+		"image_share_group_ids":      &hcldec.AttrSpec{Name: "image_share_group_ids", Type: cty.List(cty.Number), Required: false},
+		"image_create_timeout":       &hcldec.AttrSpec{Name: "image_create_timeout", Type: cty.String, Required: false},
+	}
+	return s
+}
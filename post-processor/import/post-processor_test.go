@@ -0,0 +1,382 @@
+package linodeimport
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/linode/linodego"
+	"github.com/linode/packer-plugin-linode/builder/linode"
+	"github.com/linode/packer-plugin-linode/helper"
+)
+
+func testConfig() map[string]any {
+	return map[string]any{
+		"linode_token": "bar",
+		"region":       "us-ord",
+	}
+}
+
+// newTestClient returns a client of the test API server that polls quickly.
+func newTestClient(t *testing.T, server *httptest.Server) *linodego.Client {
+	t.Helper()
+
+	common := helper.LinodeCommon{PersonalAccessToken: "secret", APIURL: server.URL}
+	client, err := common.NewClient()
+	if err != nil {
+		t.Fatalf("failed to create client: %s", err)
+	}
+	client.SetPollDelay(10 * time.Millisecond)
+	return client
+}
+
+func TestPostProcessor_ImplementsPostProcessor(t *testing.T) {
+	var raw any = &PostProcessor{}
+	if _, ok := raw.(packersdk.PostProcessor); !ok {
+		t.Fatalf("PostProcessor should be a post-processor")
+	}
+}
+
+func TestPostProcessorConfigure(t *testing.T) {
+	t.Setenv(helper.TokenEnvVar, "")
+
+	var p PostProcessor
+	if err := p.Configure(testConfig()); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if p.config.ImageLabel == "" {
+		t.Errorf("expected a default image_label")
+	}
+	if p.config.ImageCreateTimeout.Minutes() != 30 {
+		t.Errorf("expected default image_create_timeout of 30m, got %s", p.config.ImageCreateTimeout)
+	}
+	if p.config.ImageReplicationTimeout.Minutes() != 30 {
+		t.Errorf("expected default image_replication_timeout of 30m, got %s", p.config.ImageReplicationTimeout)
+	}
+	if p.config.ImageReplicationFailure != "fail" {
+		t.Errorf("expected default image_replication_failure of fail, got %q", p.config.ImageReplicationFailure)
+	}
+
+	for key, value := range map[string]any{
+		"image_replication_failure": "retry",
+		"image_replication_timeout": "-1m",
+	} {
+		config := testConfig()
+		config[key] = value
+
+		p = PostProcessor{}
+		if err := p.Configure(config); err == nil {
+			t.Errorf("should error with %s = %v", key, value)
+		}
+	}
+
+	for _, key := range []string{"linode_token", "region"} {
+		config := testConfig()
+		delete(config, key)
+
+		p = PostProcessor{}
+		if err := p.Configure(config); err == nil {
+			t.Errorf("should error when %s is missing", key)
+		}
+	}
+}
+
+func TestFindDiskImage(t *testing.T) {
+	tests := []struct {
+		files     []string
+		expected  string
+		expectErr bool
+	}{
+		{files: []string{"output/packer-debian"}, expected: "output/packer-debian"},
+		{files: []string{"output/disk.qcow2.sha256", "output/disk.img"}, expected: "output/disk.img"},
+		{files: []string{"output/manifest.json", "output/disk.raw.gz"}, expected: "output/disk.raw.gz"},
+		{files: []string{"output/a.qcow2", "output/b.vmdk"}, expectErr: true},
+		{files: nil, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := findDiskImage(tt.files)
+		if tt.expectErr {
+			if err == nil {
+				t.Errorf("%v: expected error, got %q", tt.files, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: unexpected error: %s", tt.files, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%v: got %q, expected %q", tt.files, got, tt.expected)
+		}
+	}
+}
+
+func TestCompressImage(t *testing.T) {
+	content := []byte("not really a disk image")
+	path := filepath.Join(t.TempDir(), "disk.img")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	compressed, err := isGzip(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if compressed {
+		t.Fatalf("raw file should not be detected as gzip")
+	}
+
+	gzPath, err := compressImage(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.Remove(gzPath)
+
+	compressed, err = isGzip(gzPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !compressed {
+		t.Fatalf("compressed file should be detected as gzip")
+	}
+
+	f, err := os.Open(gzPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(content) {
+		t.Errorf("got %q, expected %q", got, content)
+	}
+}
+
+func TestPostProcess_FailedUpload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "disk.img")
+	if err := os.WriteFile(path, []byte("not really a disk image"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, deleteStatus := range map[string]int{
+		"image deleted":        http.StatusOK,
+		"image deletion fails": http.StatusForbidden,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var (
+				mu      sync.Mutex
+				deleted []string
+			)
+
+			var server *httptest.Server
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+
+				switch {
+				case r.Method == http.MethodPost && r.URL.Path == "/v4/images/upload":
+					_, _ = w.Write([]byte(`{"image": {"id": "private/1", "status": "pending_upload"},
+						"upload_to": "` + server.URL + `/upload"}`))
+				case r.Method == http.MethodPut && r.URL.Path == "/upload":
+					w.WriteHeader(http.StatusInternalServerError)
+				case r.Method == http.MethodDelete:
+					mu.Lock()
+					deleted = append(deleted, r.URL.Path)
+					mu.Unlock()
+					w.WriteHeader(deleteStatus)
+					if deleteStatus != http.StatusOK {
+						_, _ = w.Write([]byte(`{"errors": [{"reason": "Unauthorized"}]}`))
+						return
+					}
+					_, _ = w.Write([]byte(`{}`))
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			config := testConfig()
+			config["api_url"] = server.URL
+
+			var p PostProcessor
+			if err := p.Configure(config); err != nil {
+				t.Fatalf("should not have error: %s", err)
+			}
+
+			source := &packersdk.MockArtifact{FilesValue: []string{path}}
+			_, _, _, err := p.PostProcess(context.Background(), packersdk.TestUi(t), source)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), "failed to upload image") {
+				t.Errorf("error %q should report the upload failure", err)
+			}
+			if deleteStatus != http.StatusOK && !strings.Contains(err.Error(), "failed to delete image private/1") {
+				t.Errorf("error %q should report the deletion failure", err)
+			}
+
+			if len(deleted) != 1 || deleted[0] != "/v4/images/private/1" {
+				t.Errorf("got deletions %v, expected the uploaded image to be deleted", deleted)
+			}
+		})
+	}
+}
+
+func TestPostProcessorFinishImage_KeepsRegion(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method != http.MethodPost || r.URL.Path != "/v4/images/private/1/regions" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			return
+		}
+
+		var opts linodego.ImageReplicateOptions
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			t.Errorf("failed to decode the request: %s", err)
+		}
+		requested = opts.Regions
+
+		_, _ = w.Write([]byte(`{"id": "private/1", "regions": [
+			{"region": "us-ord", "status": "available"},
+			{"region": "eu-central", "status": "available"}
+		]}`))
+	}))
+	defer server.Close()
+
+	p := PostProcessor{config: Config{Region: "us-ord", ImageRegions: []string{"eu-central"}}}
+	if err := p.finishImage(context.Background(), newTestClient(t, server), packersdk.TestUi(t), "private/1"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expected := []string{"us-ord", "eu-central"}; !reflect.DeepEqual(requested, expected) {
+		t.Errorf("got replication regions %v, expected %v", requested, expected)
+	}
+}
+
+func TestDeleteFailedImage(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		deleted []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodDelete {
+			mu.Lock()
+			deleted = append(deleted, r.URL.Path)
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		if r.URL.Path != "/v4/images/sharegroups/10/images" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"page": 1, "pages": 1, "results": 1, "data": [
+			{"id": "shared/1", "image_sharing": {"shared_by": {"source_image_id": "private/1"}}}
+		]}`))
+	}))
+	defer server.Close()
+
+	shareErr := errors.New("failed to share the image")
+	err := deleteFailedImage(newTestClient(t, server), packersdk.TestUi(t), "private/1", []int{10}, shareErr)
+	if !errors.Is(err, shareErr) {
+		t.Errorf("got error %v, expected the import error", err)
+	}
+
+	expected := []string{"/v4/images/sharegroups/10/images/shared/1", "/v4/images/private/1"}
+	if !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("got deletions %v, expected %v", deleted, expected)
+	}
+}
+
+func TestPostProcessorFinishImage_ReplicationFailure(t *testing.T) {
+	// The replica in eu-central never becomes available
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "private/1", "regions": [
+			{"region": "us-ord", "status": "available"},
+			{"region": "eu-central", "status": "replicating"}
+		]}`))
+	}))
+	defer server.Close()
+
+	for policy, expectError := range map[string]bool{
+		helper.ReplicationFailureFail:   true,
+		helper.ReplicationFailureWarn:   false,
+		helper.ReplicationFailureIgnore: false,
+	} {
+		t.Run(policy, func(t *testing.T) {
+			p := PostProcessor{config: Config{
+				Region:                  "us-ord",
+				ImageRegions:            []string{"eu-central"},
+				ImageReplicationTimeout: 50 * time.Millisecond,
+				ImageReplicationFailure: policy,
+			}}
+
+			err := p.finishImage(context.Background(), newTestClient(t, server), packersdk.TestUi(t), "private/1")
+			if expectError != (err != nil) {
+				t.Fatalf("got error %v, expected error: %t", err, expectError)
+			}
+
+			var replicationErr *helper.ReplicationError
+			if err != nil && !errors.As(err, &replicationErr) {
+				t.Errorf("got error %v, expected a replication error", err)
+			}
+		})
+	}
+}
+
+func TestPostProcessorStateData(t *testing.T) {
+	p := PostProcessor{config: Config{
+		Region:             "us-ord",
+		ImageRegions:       []string{"eu-central", "ap-south"},
+		ImageShareGroupIDs: []int{10},
+	}}
+	image := &linodego.Image{
+		Size:         1024,
+		Capabilities: []string{"cloud-init"},
+		Regions: []linodego.ImageRegion{
+			{Region: "us-ord", Status: linodego.ImageRegionStatusAvailable},
+			{Region: "eu-central", Status: linodego.ImageRegionStatusAvailable},
+			{Region: "ap-south", Status: linodego.ImageRegionStatusReplicating},
+		},
+	}
+
+	artifact := linode.Artifact{StateData: p.stateData(image, &packersdk.MockArtifact{})}
+
+	if got := artifact.State("image_regions"); !reflect.DeepEqual(got, []string{"us-ord", "eu-central"}) {
+		t.Errorf("got image_regions %v", got)
+	}
+	if got := artifact.State("image_replication_failed_regions"); !reflect.DeepEqual(got, []string{"ap-south"}) {
+		t.Errorf("got image_replication_failed_regions %v", got)
+	}
+	if artifact.State("cloud_init") != true || artifact.State("image_size") != 1024 {
+		t.Errorf("got cloud_init %v and image_size %v", artifact.State("cloud_init"), artifact.State("image_size"))
+	}
+	if got := artifact.State("image_share_group_ids"); !reflect.DeepEqual(got, []int{10}) {
+		t.Errorf("got image_share_group_ids %v", got)
+	}
+}